@@ -0,0 +1,183 @@
+package multimap
+
+// Semantics selects how the set-algebra operations treat repeated key-value pairs.
+type Semantics int
+
+const (
+	// Bag semantics count how many times each key-value pair is contained in a multimap,
+	// as for multimaps holding duplicate key-value pairs like slicemultimap.
+	Bag Semantics = iota
+	// Set semantics only consider whether a key-value pair is contained in a multimap,
+	// and every key-value pair of the result is distinct.
+	Set
+)
+
+// Union returns a new multimap, created by newMultiMap, holding the key-value pairs contained in a or in b.
+//
+// With Bag semantics a key-value pair is contained as many times as in a or b, whichever holds it more often.
+// The values of a key keep the ordering of a, followed by the remaining values of b in their ordering.
+func Union[K comparable, V comparable, M MultiMap[K, V]](a, b MultiMap[K, V], semantics Semantics, newMultiMap func() M) M {
+	result := newMultiMap()
+	bSets := sets(b)
+	for key, x := range a.Sets() {
+		y := bSets[key]
+		result.PutAll(key, union(x, y, semantics))
+	}
+	for key, y := range b.Sets() {
+		if !a.ContainsKey(key) {
+			result.PutAll(key, union(nil, y, semantics))
+		}
+	}
+	return result
+}
+
+// Intersection returns a new multimap, created by newMultiMap, holding the key-value pairs contained in both a and b.
+//
+// With Bag semantics a key-value pair is contained as many times as in a or b, whichever holds it less often.
+// The values of a key keep the ordering of a.
+func Intersection[K comparable, V comparable, M MultiMap[K, V]](a, b MultiMap[K, V], semantics Semantics, newMultiMap func() M) M {
+	result := newMultiMap()
+	bSets := sets(b)
+	for key, x := range a.Sets() {
+		if y, found := bSets[key]; found {
+			result.PutAll(key, intersection(x, y, semantics))
+		}
+	}
+	return result
+}
+
+// Difference returns a new multimap, created by newMultiMap, holding the key-value pairs contained in a but not in b.
+//
+// With Bag semantics every occurrence of a key-value pair in b cancels out one occurrence in a,
+// starting with the first one. The values of a key keep the ordering of a.
+func Difference[K comparable, V comparable, M MultiMap[K, V]](a, b MultiMap[K, V], semantics Semantics, newMultiMap func() M) M {
+	result := newMultiMap()
+	bSets := sets(b)
+	for key, x := range a.Sets() {
+		y := bSets[key]
+		result.PutAll(key, difference(x, y, semantics))
+	}
+	return result
+}
+
+// SymmetricDifference returns a new multimap, created by newMultiMap, holding the key-value pairs
+// contained in either a or b, but not in both.
+//
+// With Bag semantics a key-value pair is contained as many times as a and b hold it more than the other one.
+// The values of a key remaining from a come first, followed by the values remaining from b.
+func SymmetricDifference[K comparable, V comparable, M MultiMap[K, V]](a, b MultiMap[K, V], semantics Semantics, newMultiMap func() M) M {
+	result := newMultiMap()
+	bSets := sets(b)
+	for key, x := range a.Sets() {
+		y := bSets[key]
+		result.PutAll(key, difference(x, y, semantics))
+		result.PutAll(key, difference(y, x, semantics))
+	}
+	for key, y := range b.Sets() {
+		if !a.ContainsKey(key) {
+			result.PutAll(key, difference(y, nil, semantics))
+		}
+	}
+	return result
+}
+
+// union returns the values of x followed by the values of y not matched by a value of x.
+func union[V comparable](x, y []V, semantics Semantics) []V {
+	if semantics == Set {
+		values := distinct(x)
+		seen := presence(values)
+		for _, value := range y {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+	values := append([]V(nil), x...)
+	counts := count(x)
+	for _, value := range y {
+		if counts[value] > 0 {
+			counts[value]--
+		} else {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// intersection returns the values of x matched by a value of y.
+func intersection[V comparable](x, y []V, semantics Semantics) []V {
+	var values []V
+	if semantics == Set {
+		contained := presence(y)
+		for _, value := range distinct(x) {
+			if contained[value] {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+	counts := count(y)
+	for _, value := range x {
+		if counts[value] > 0 {
+			counts[value]--
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// difference returns the values of x not matched by a value of y.
+func difference[V comparable](x, y []V, semantics Semantics) []V {
+	var values []V
+	if semantics == Set {
+		contained := presence(y)
+		for _, value := range distinct(x) {
+			if !contained[value] {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+	counts := count(y)
+	for _, value := range x {
+		if counts[value] > 0 {
+			counts[value]--
+		} else {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// distinct returns the first occurrence of every value in order.
+func distinct[V comparable](values []V) []V {
+	seen := make(map[V]bool, len(values))
+	var result []V
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// presence returns the set of values.
+func presence[V comparable](values []V) map[V]bool {
+	set := make(map[V]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// count returns the number of occurrences of every value.
+func count[V comparable](values []V) map[V]int {
+	counts := make(map[V]int, len(values))
+	for _, value := range values {
+		counts[value]++
+	}
+	return counts
+}
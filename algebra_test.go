@@ -0,0 +1,115 @@
+package multimap_test
+
+import (
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+	"github.com/rafos/go-multimap/treemultimap"
+)
+
+// operands returns two multimaps sharing the key "a", each holding a key of its own.
+func operands() (a, b *slicemultimap.MultiMap[string, int]) {
+	a = slicemultimap.New[string, int]()
+	a.PutAll("a", []int{1, 1, 2, 3})
+	a.Put("b", 4)
+	b = slicemultimap.New[string, int]()
+	b.PutAll("a", []int{3, 1, 5, 5})
+	b.Put("c", 6)
+	return a, b
+}
+
+func TestSetAlgebra(t *testing.T) {
+	type operation func(a, b multimap.MultiMap[string, int], semantics multimap.Semantics, newMultiMap func() *slicemultimap.MultiMap[string, int]) *slicemultimap.MultiMap[string, int]
+
+	tests := []struct {
+		name      string
+		operation operation
+		semantics multimap.Semantics
+		expected  map[string][]int
+	}{
+		{"bag union", multimap.Union[string, int, *slicemultimap.MultiMap[string, int]], multimap.Bag,
+			map[string][]int{"a": {1, 1, 2, 3, 5, 5}, "b": {4}, "c": {6}}},
+		{"set union", multimap.Union[string, int, *slicemultimap.MultiMap[string, int]], multimap.Set,
+			map[string][]int{"a": {1, 2, 3, 5}, "b": {4}, "c": {6}}},
+		{"bag intersection", multimap.Intersection[string, int, *slicemultimap.MultiMap[string, int]], multimap.Bag,
+			map[string][]int{"a": {1, 3}}},
+		{"set intersection", multimap.Intersection[string, int, *slicemultimap.MultiMap[string, int]], multimap.Set,
+			map[string][]int{"a": {1, 3}}},
+		{"bag difference", multimap.Difference[string, int, *slicemultimap.MultiMap[string, int]], multimap.Bag,
+			map[string][]int{"a": {1, 2}, "b": {4}}},
+		{"set difference", multimap.Difference[string, int, *slicemultimap.MultiMap[string, int]], multimap.Set,
+			map[string][]int{"a": {2}, "b": {4}}},
+		{"bag symmetric difference", multimap.SymmetricDifference[string, int, *slicemultimap.MultiMap[string, int]], multimap.Bag,
+			map[string][]int{"a": {1, 2, 5, 5}, "b": {4}, "c": {6}}},
+		{"set symmetric difference", multimap.SymmetricDifference[string, int, *slicemultimap.MultiMap[string, int]], multimap.Set,
+			map[string][]int{"a": {2, 5}, "b": {4}, "c": {6}}},
+	}
+
+	for _, test := range tests {
+		a, b := operands()
+		actual := test.operation(a, b, test.semantics, slicemultimap.New[string, int])
+
+		expected := slicemultimap.New[string, int]()
+		for key, values := range test.expected {
+			expected.PutAll(key, values)
+		}
+		if !multimap.Equal[string, int](actual, expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, expected.Entries(), actual.Entries())
+		}
+
+		if a.Size() != 5 || b.Size() != 5 {
+			t.Errorf("%s: expected the operands to be unchanged, got %v and %v", test.name, a.Entries(), b.Entries())
+		}
+	}
+}
+
+func TestSetAlgebraEmpty(t *testing.T) {
+	a, _ := operands()
+	empty := slicemultimap.New[string, int]()
+
+	for _, semantics := range []multimap.Semantics{multimap.Bag, multimap.Set} {
+		if actual := multimap.Union(empty, empty, semantics, slicemultimap.New[string, int]); !actual.Empty() {
+			t.Errorf("expected %v, got %v", []multimap.Entry[string, int]{}, actual.Entries())
+		}
+		if actual := multimap.Intersection[string, int](a, empty, semantics, slicemultimap.New[string, int]); !actual.Empty() {
+			t.Errorf("expected %v, got %v", []multimap.Entry[string, int]{}, actual.Entries())
+		}
+		if actual := multimap.Difference[string, int](a, a, semantics, slicemultimap.New[string, int]); !actual.Empty() {
+			t.Errorf("expected %v, got %v", []multimap.Entry[string, int]{}, actual.Entries())
+		}
+		if actual := multimap.SymmetricDifference[string, int](a, a, semantics, slicemultimap.New[string, int]); !actual.Empty() {
+			t.Errorf("expected %v, got %v", []multimap.Entry[string, int]{}, actual.Entries())
+		}
+	}
+
+	if actual := multimap.Difference[string, int](a, empty, multimap.Bag, slicemultimap.New[string, int]); !multimap.Equal[string, int](actual, a) {
+		t.Errorf("expected %v, got %v", a.Entries(), actual.Entries())
+	}
+}
+
+func TestSetAlgebraAcrossImplementations(t *testing.T) {
+	roles := setmultimap.New[string, string]()
+	roles.PutAll("admin", []string{"read", "write", "delete"})
+	roles.PutAll("editor", []string{"read", "write"})
+
+	grants := slicemultimap.New[string, string]()
+	grants.PutAll("editor", []string{"publish", "read"})
+	grants.Put("viewer", "read")
+
+	revoked := treemultimap.NewOrdered[string, string]()
+	revoked.Put("admin", "delete")
+	revoked.Put("editor", "read")
+
+	union := multimap.Union[string, string](roles, grants, multimap.Set, treemultimap.NewOrdered[string, string])
+	actual := multimap.Difference[string, string](union, revoked, multimap.Set, setmultimap.New[string, string])
+
+	expected := setmultimap.New[string, string]()
+	expected.PutAll("admin", []string{"read", "write"})
+	expected.PutAll("editor", []string{"write", "publish"})
+	expected.Put("viewer", "read")
+	if !multimap.EqualUnordered[string, string](actual, expected) {
+		t.Errorf("expected %v, got %v", expected.Entries(), actual.Entries())
+	}
+}
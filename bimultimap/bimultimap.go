@@ -0,0 +1,338 @@
+// Package bimultimap implements a multimap that keeps a reverse index from values to keys.
+//
+// A bimultimap is a multimap that cannot hold duplicate key-value pairs.
+// Adding a key-value pair that's already in the multimap has no effect.
+//
+// Besides the map of sets from keys to values, every key-value pair is also recorded
+// in a map of sets from values to keys. Looking up the keys of a value with GetKeys,
+// ContainsValue and RemoveValue therefore do not scan all keys of the multimap,
+// at the cost of storing every key-value pair twice.
+//
+// Elements are unordered in the map and values are unordered for a given key.
+//
+// Structure is not thread safe.
+package bimultimap
+
+import (
+	"encoding"
+	"encoding/gob"
+	"iter"
+	"maps"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+var (
+	_ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+	_ encoding.BinaryMarshaler    = &MultiMap[any, any]{}
+	_ encoding.BinaryUnmarshaler  = &MultiMap[any, any]{}
+	_ gob.GobEncoder              = &MultiMap[any, any]{}
+	_ gob.GobDecoder              = &MultiMap[any, any]{}
+)
+
+// MultiMap holds the elements in go's native maps of sets, indexed both by key and by value.
+type MultiMap[K comparable, V comparable] struct {
+	forward map[K]map[V]struct{}
+	reverse map[V]map[K]struct{}
+	size    int
+}
+
+// New instantiates a new multimap.
+func New[K comparable, V comparable]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{forward: make(map[K]map[V]struct{}), reverse: make(map[V]map[K]struct{})}
+}
+
+// Get searches the element in the multimap by key.
+// It returns its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	set, found := m.forward[key]
+	if !found {
+		return nil, false
+	}
+	return members(set), true
+}
+
+// GetKeys searches the element in the multimap by value.
+// It returns the keys associated with the value or nil if value is not found in multimap.
+// Second return parameter is true if value was found, otherwise false.
+func (m *MultiMap[K, V]) GetKeys(value V) (keys []K, found bool) {
+	set, found := m.reverse[value]
+	if !found {
+		return nil, false
+	}
+	return members(set), true
+}
+
+// Put stores a key-value pair in this multimap.
+// It has no effect if the key-value pair is already present.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	if !add(m.forward, key, value) {
+		return
+	}
+	add(m.reverse, value, key)
+	m.size++
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	for _, value := range values {
+		m.Put(key, value)
+	}
+}
+
+// Contains returns true if this multimap contains a key-value pair with the key key and the value value.
+func (m *MultiMap[K, V]) Contains(key K, value V) (found bool) {
+	_, found = m.forward[key][value]
+	return
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) (found bool) {
+	_, found = m.forward[key]
+	return
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) (found bool) {
+	_, found = m.reverse[value]
+	return
+}
+
+// Remove removes the key-value pair from this multimap, if such exists.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	if !remove(m.forward, key, value) {
+		return
+	}
+	remove(m.reverse, value, key)
+	m.size--
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	for value := range m.forward[key] {
+		remove(m.reverse, value, key)
+	}
+	m.size -= len(m.forward[key])
+	delete(m.forward, key)
+}
+
+// RemoveValue removes all keys associated with the value from the multimap.
+func (m *MultiMap[K, V]) RemoveValue(value V) {
+	for key := range m.reverse[value] {
+		remove(m.forward, key, value)
+	}
+	m.size -= len(m.reverse[value])
+	delete(m.reverse, value)
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	return m.size == 0
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	return m.size
+}
+
+// Keys returns a view collection containing the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+func (m *MultiMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.size)
+	for key := range m.KeysSeq() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// KeySet returns all distinct keys contained in this multimap.
+func (m *MultiMap[K, V]) KeySet() []K {
+	keys := make([]K, 0, len(m.forward))
+	for key := range m.forward {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Values returns all values from each key-value pair contained in this multimap.
+// The same value is returned once for every key it is associated with. (size of Values() = MultiMap.Size()).
+func (m *MultiMap[K, V]) Values() []V {
+	values := make([]V, 0, m.size)
+	for value := range m.ValuesSeq() {
+		values = append(values, value)
+	}
+	return values
+}
+
+// ValueSet returns all distinct values contained in this multimap.
+func (m *MultiMap[K, V]) ValueSet() []V {
+	values := make([]V, 0, len(m.reverse))
+	for value := range m.reverse {
+		values = append(values, value)
+	}
+	return values
+}
+
+// Entries view collection of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances.
+// Retrieving the key and value from the entries result will be as trivial as:
+//   - var entry = m.Entries()[0]
+//   - var key = entry.Key
+//   - var value = entry.Value
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	entries := make([]multimap.Entry[K, V], 0, m.size)
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[K, V]{Key: key, Value: value})
+	}
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, set := range m.forward {
+			for value := range set {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key, set := range m.forward {
+			for range set {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap.
+// The same value is yielded once for every key it is associated with.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for value, set := range m.reverse {
+			for range set {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with their values.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for key, set := range m.forward {
+			if !yield(key, members(set)) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.forward = make(map[K]map[V]struct{})
+	m.reverse = make(map[V]map[K]struct{})
+	m.size = 0
+}
+
+// Clone returns a deep copy of the multimap.
+// Both indexes are copied, so the clone and the multimap can be modified independently.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	c := &MultiMap[K, V]{
+		forward: make(map[K]map[V]struct{}, len(m.forward)),
+		reverse: make(map[V]map[K]struct{}, len(m.reverse)),
+		size:    m.size,
+	}
+	for key, set := range m.forward {
+		c.forward[key] = maps.Clone(set)
+	}
+	for value, set := range m.reverse {
+		c.reverse[value] = maps.Clone(set)
+	}
+	return c
+}
+
+// MarshalBinary encodes the multimap into a compact binary form.
+// Every key is written once, followed by the number of its values and the values themselves.
+// The reverse index is not written, it is rebuilt by UnmarshalBinary.
+func (m *MultiMap[K, V]) MarshalBinary() ([]byte, error) {
+	return codec.AppendMultiMap(nil, len(m.forward), m.Sets())
+}
+
+// UnmarshalBinary decodes a multimap encoded by MarshalBinary, replacing all elements of the multimap.
+// Corrupt input yields an error and leaves the multimap unchanged.
+func (m *MultiMap[K, V]) UnmarshalBinary(data []byte) error {
+	sets, err := codec.DecodeMultiMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.Clear()
+	for _, set := range sets {
+		m.PutAll(set.Key, set.Values)
+	}
+	return nil
+}
+
+// GobEncode encodes the multimap for encoding/gob using MarshalBinary.
+func (m *MultiMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode decodes the multimap for encoding/gob using UnmarshalBinary.
+func (m *MultiMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
+
+// add adds the member to the set of the element of index.
+// It returns false if the member was already present.
+func add[E comparable, M comparable](index map[E]map[M]struct{}, element E, member M) bool {
+	set, found := index[element]
+	if !found {
+		set = make(map[M]struct{})
+		index[element] = set
+	} else if _, found := set[member]; found {
+		return false
+	}
+	set[member] = struct{}{}
+	return true
+}
+
+// remove removes the member from the set of the element of index, dropping the set once it is empty.
+// It returns false if the member was not present.
+func remove[E comparable, M comparable](index map[E]map[M]struct{}, element E, member M) bool {
+	set := index[element]
+	if _, found := set[member]; !found {
+		return false
+	}
+	delete(set, member)
+	if len(set) == 0 {
+		delete(index, element)
+	}
+	return true
+}
+
+// members returns the members of a set.
+func members[M comparable](set map[M]struct{}) []M {
+	result := make([]M, 0, len(set))
+	for member := range set {
+		result = append(result, member)
+	}
+	return result
+}
@@ -0,0 +1,447 @@
+package bimultimap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
+)
+
+func TestClear(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(1, "x")
+	m.Put(2, "x")
+	m.Put(1, "a")
+
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if actualEmpty := m.Empty(); actualEmpty != false {
+		t.Errorf("expected an empty multimap: %v, got %v", false, actualEmpty)
+	}
+
+	m.Clear()
+
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+	if actualEmpty := m.Empty(); actualEmpty != true {
+		t.Errorf("expected an empty multimap: %v, got %v", true, actualEmpty)
+	}
+	if m.ContainsValue("x") {
+		t.Errorf("expected the reverse index to be cleared")
+	}
+	checkInvariants(t, m)
+}
+
+func TestPut(t *testing.T) {
+	m := New[string, int]()
+	m.Put("web1", 443)
+	m.Put("web1", 80)
+	m.Put("web2", 443)
+	m.Put("web2", 443)
+	m.PutAll("db1", []int{5432, 22})
+
+	if actualValue := m.Size(); actualValue != 5 {
+		t.Errorf("expected %v, got %v", 5, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []string{"web1", "web2", "db1"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ValueSet(), []int{443, 80, 5432, 22}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, found := m.Get("web1"); !found || !sameElements(actualValue, []int{443, 80}) {
+		t.Errorf("expected %v, got %v", []int{443, 80}, actualValue)
+	}
+	if actualValue, found := m.Get("web3"); found || actualValue != nil {
+		t.Errorf("expected %v, got %v", nil, actualValue)
+	}
+	checkInvariants(t, m)
+}
+
+func TestGetKeys(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("web1", []int{443, 80})
+	m.Put("web2", 443)
+	m.Put("db1", 5432)
+
+	if actualValue, found := m.GetKeys(443); !found || !sameElements(actualValue, []string{"web1", "web2"}) {
+		t.Errorf("expected %v, got %v", []string{"web1", "web2"}, actualValue)
+	}
+	if actualValue, found := m.GetKeys(22); found || actualValue != nil {
+		t.Errorf("expected %v, got %v", nil, actualValue)
+	}
+	if !m.ContainsValue(5432) || m.ContainsValue(22) {
+		t.Errorf("expected ContainsValue to use the reverse index")
+	}
+
+	m.Remove("web2", 443)
+	if actualValue, _ := m.GetKeys(443); !sameElements(actualValue, []string{"web1"}) {
+		t.Errorf("expected %v, got %v", []string{"web1"}, actualValue)
+	}
+	m.RemoveAll("web1")
+	if _, found := m.GetKeys(443); found {
+		t.Errorf("expected 443 to be removed from the reverse index")
+	}
+	checkInvariants(t, m)
+}
+
+func TestRemove(t *testing.T) {
+	m := New[int, string]()
+	m.PutAll(1, []string{"a", "b"})
+	m.Put(2, "a")
+
+	m.Remove(1, "c")
+	m.Remove(3, "a")
+	if actualValue := m.Size(); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+
+	m.Remove(1, "a")
+	if m.Contains(1, "a") || !m.Contains(2, "a") {
+		t.Errorf("expected only the pair 1-a to be removed")
+	}
+	m.Remove(1, "b")
+	if m.ContainsKey(1) || m.ContainsValue("b") {
+		t.Errorf("expected key 1 and value b to be removed")
+	}
+	if actualValue := m.Size(); actualValue != 1 {
+		t.Errorf("expected %v, got %v", 1, actualValue)
+	}
+	checkInvariants(t, m)
+}
+
+func TestRemoveValue(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("web1", []int{443, 80})
+	m.PutAll("web2", []int{443})
+	m.Put("db1", 5432)
+
+	m.RemoveValue(443)
+	if m.ContainsValue(443) || m.Contains("web1", 443) {
+		t.Errorf("expected every pair with value 443 to be removed")
+	}
+	if m.ContainsKey("web2") {
+		t.Errorf("expected key web2 without values to be removed")
+	}
+	if actualValue, _ := m.Get("web1"); !sameElements(actualValue, []int{80}) {
+		t.Errorf("expected %v, got %v", []int{80}, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+
+	m.RemoveValue(22)
+	if actualValue := m.Size(); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+	checkInvariants(t, m)
+}
+
+func TestRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	m := New[int, int]()
+	model := make(map[multimap.Entry[int, int]]bool)
+
+	for i := 0; i < 10000; i++ {
+		key, value := r.Intn(20), r.Intn(20)
+		switch r.Intn(6) {
+		case 0, 1:
+			m.Put(key, value)
+			model[multimap.Entry[int, int]{Key: key, Value: value}] = true
+		case 2:
+			m.Remove(key, value)
+			delete(model, multimap.Entry[int, int]{Key: key, Value: value})
+		case 3:
+			m.RemoveAll(key)
+			for entry := range model {
+				if entry.Key == key {
+					delete(model, entry)
+				}
+			}
+		case 4:
+			m.RemoveValue(value)
+			for entry := range model {
+				if entry.Value == value {
+					delete(model, entry)
+				}
+			}
+		case 5:
+			m.PutAll(key, []int{value, value + 1})
+			model[multimap.Entry[int, int]{Key: key, Value: value}] = true
+			model[multimap.Entry[int, int]{Key: key, Value: value + 1}] = true
+		}
+
+		if i%100 == 0 {
+			checkInvariants(t, m)
+			if t.Failed() {
+				t.Fatalf("invariants broken after %d operations", i+1)
+			}
+		}
+		if actualValue := m.Size(); actualValue != len(model) {
+			t.Fatalf("after %d operations: expected %v, got %v", i+1, len(model), actualValue)
+		}
+	}
+
+	checkInvariants(t, m)
+	for entry := range model {
+		if !m.Contains(entry.Key, entry.Value) {
+			t.Errorf("expected %v to be contained", entry)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return New[string, string]()
+	}, multimaptest.Set)
+}
+
+func TestIterators(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(1, "x")
+	m.Put(2, "x")
+	m.PutAll(4, []string{"d", "y"})
+
+	var entries []multimap.Entry[int, string]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[int, string]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keys []int
+	for key := range m.KeysSeq() {
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := keys, []int{5, 1, 2, 4, 4}; !sameElements(actualValue, expectedValue) || len(actualValue) != m.Size() {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var values []string
+	for value := range m.ValuesSeq() {
+		values = append(values, value)
+	}
+	if actualValue, expectedValue := values, []string{"e", "x", "x", "d", "y"}; !sameElements(actualValue, expectedValue) || len(actualValue) != m.Size() {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	for key, values := range m.Sets() {
+		if expectedValue, _ := m.Get(key); !sameElements(values, expectedValue) {
+			t.Errorf("key %d: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+
+	count := 0
+	for range m.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Errorf("expected %v, got %v", 3, count)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("a", []int{3, 1, 2})
+	m.Put("b", 4)
+
+	c := m.Clone()
+	if !multimap.EqualUnordered[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+
+	c.Put("a", 5)
+	c.RemoveValue(4)
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.ContainsValue(4) || m.ContainsValue(5) {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+	checkInvariants(t, m)
+	checkInvariants(t, c)
+}
+
+func TestMarshalBinary(t *testing.T) {
+	m := New[int, string]()
+	m.PutAll(2, []string{"b", "a"})
+	m.Put(1, "a")
+	m.Put(3, "")
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := New[int, string]()
+	actual.Put(4, "d")
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := actual.GetKeys("a"); !sameElements(actualValue, []int{1, 2}) {
+		t.Errorf("expected %v, got %v", []int{1, 2}, actualValue)
+	}
+	checkInvariants(t, actual)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual = New[int, string]()
+	if err := gob.NewDecoder(&buf).Decode(actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if err := actual.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated at %d: expected an error", i)
+		}
+	}
+	checkInvariants(t, actual)
+}
+
+// checkInvariants verifies that the reverse index mirrors the forward index,
+// that no empty set is kept and that the size matches the number of key-value pairs.
+func checkInvariants[K comparable, V comparable](t *testing.T, m *MultiMap[K, V]) {
+	t.Helper()
+	size := 0
+	for key, set := range m.forward {
+		if len(set) == 0 {
+			t.Errorf("empty set kept for key %v", key)
+		}
+		for value := range set {
+			if _, found := m.reverse[value][key]; !found {
+				t.Errorf("pair %v-%v missing from the reverse index", key, value)
+			}
+		}
+		size += len(set)
+	}
+	reverseSize := 0
+	for value, set := range m.reverse {
+		if len(set) == 0 {
+			t.Errorf("empty set kept for value %v", value)
+		}
+		for key := range set {
+			if _, found := m.forward[key][value]; !found {
+				t.Errorf("pair %v-%v missing from the forward index", key, value)
+			}
+		}
+		reverseSize += len(set)
+	}
+	if size != m.size || reverseSize != m.size {
+		t.Errorf("expected size %v, got %v forward and %v reverse pairs", m.size, size, reverseSize)
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av == bv {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check equality of entries.
+func sameEntries[K comparable, V comparable](a []multimap.Entry[K, V], b []multimap.Entry[K, V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av == bv {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func benchmarkContainsValue(b *testing.B, m *MultiMap[int, int], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.ContainsValue(n)
+		}
+	}
+}
+
+func benchmarkGetKeys(b *testing.B, m *MultiMap[int, int], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.GetKeys(n)
+		}
+	}
+}
+
+func newBenchmarkMultiMap(size int) *MultiMap[int, int] {
+	m := New[int, int]()
+	for n := 0; n < size; n++ {
+		m.Put(n%(size/10+1), n)
+	}
+	return m
+}
+
+func BenchmarkMultiMapContainsValue1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := newBenchmarkMultiMap(size)
+	b.StartTimer()
+	benchmarkContainsValue(b, m, size)
+}
+
+func BenchmarkMultiMapContainsValue100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := newBenchmarkMultiMap(size)
+	b.StartTimer()
+	benchmarkContainsValue(b, m, size)
+}
+
+func BenchmarkMultiMapGetKeys1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := newBenchmarkMultiMap(size)
+	b.StartTimer()
+	benchmarkGetKeys(b, m, size)
+}
+
+func BenchmarkMultiMapGetKeys100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := newBenchmarkMultiMap(size)
+	b.StartTimer()
+	benchmarkGetKeys(b, m, size)
+}
@@ -0,0 +1,451 @@
+// Package boundedmultimap implements a multimap with capacity limits and eviction.
+//
+// A boundedmultimap is a multimap that can hold duplicate key-value pairs
+// and that maintains the insertion ordering of values for a given key, like a slicemultimap,
+// but it never grows beyond the limits given to its constructor:
+//
+//   - MaxValuesPerKey bounds the number of values of every key. When a key is full,
+//     PerKeyPolicy decides whether its oldest value or its newest value is dropped, or the new value is rejected.
+//   - MaxSize bounds the total number of key-value pairs. When the multimap is full,
+//     all values of the least recently used (LRU) or least frequently used (LFU) key are evicted.
+//     The key being put is never evicted this way; if it is the only key, its oldest values are dropped instead.
+//
+// Put and Get count as uses of a key, while Peek, the other lookups and the iterators do not;
+// multimap.Equal and the set-algebra helpers of the multimap package read multimaps through Sets,
+// so comparing or combining a boundedmultimap leaves its eviction order unchanged. Values dropped or evicted to respect the limits
+// are reported to the OnEvict callback; values removed explicitly by Remove, RemoveAll or Clear are not.
+//
+// Elements are unordered in the map.
+//
+// Structure is not thread safe. Get records the use of a key under an internal lock,
+// which Clone also holds while it copies the usage of the keys,
+// so the multimap may be wrapped in a syncmultimap, which runs readers concurrently.
+package boundedmultimap
+
+import (
+	"container/heap"
+	"iter"
+	"slices"
+	"sync"
+
+	"github.com/rafos/go-multimap"
+)
+
+var _ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+
+// PerKeyPolicy decides what happens when a value is put for a key that already holds MaxValuesPerKey values.
+type PerKeyPolicy int
+
+const (
+	// DropOldest drops the first value of the key to make room for the new value.
+	DropOldest PerKeyPolicy = iota
+	// DropNewest drops the last value of the key to make room for the new value.
+	DropNewest
+	// Reject does not store the new value.
+	Reject
+)
+
+// Eviction decides which key is evicted when the multimap holds MaxSize key-value pairs.
+type Eviction int
+
+const (
+	// LRU evicts the key that was least recently used.
+	LRU Eviction = iota
+	// LFU evicts the key that was least frequently used, the least recently used one among equally used keys.
+	LFU
+)
+
+// Options configures the limits of a multimap.
+// A zero limit means unlimited.
+type Options[K comparable, V comparable] struct {
+	MaxValuesPerKey int
+	PerKeyPolicy    PerKeyPolicy
+
+	MaxSize  int
+	Eviction Eviction
+
+	// OnEvict, if set, is called for every key-value pair dropped or evicted to respect the limits.
+	// It must not modify the multimap.
+	OnEvict func(key K, value V)
+}
+
+// bucket holds the values of a key together with its usage.
+type bucket[K comparable, V comparable] struct {
+	key    K
+	values []V
+
+	uses  int
+	tick  uint64
+	index int
+}
+
+// MultiMap holds the elements in go's native map and orders its keys in a heap by eviction priority.
+type MultiMap[K comparable, V comparable] struct {
+	m       map[K]*bucket[K, V]
+	size    int
+	options Options[K, V]
+
+	// mu guards the usage of the keys and their order, which Get modifies and Clone reads.
+	mu    sync.Mutex
+	order order[K, V]
+	tick  uint64
+}
+
+// New instantiates a new multimap with the limits of options.
+// It panics if a limit is negative.
+func New[K comparable, V comparable](options Options[K, V]) *MultiMap[K, V] {
+	if options.MaxValuesPerKey < 0 || options.MaxSize < 0 {
+		panic("boundedmultimap: negative limit")
+	}
+	m := &MultiMap[K, V]{m: make(map[K]*bucket[K, V]), options: options}
+	m.order.eviction = options.Eviction
+	return m
+}
+
+// Get searches the element in the multimap by key and records a use of the key.
+// It returns a copy of its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	b, found := m.m[key]
+	if !found {
+		return nil, false
+	}
+	m.mu.Lock()
+	m.use(b)
+	m.mu.Unlock()
+	return slices.Clone(b.values), true
+}
+
+// Peek searches the element in the multimap by key without recording a use of the key.
+// It returns a copy of its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Peek(key K) (values []V, found bool) {
+	b, found := m.m[key]
+	if !found {
+		return nil, false
+	}
+	return slices.Clone(b.values), true
+}
+
+// Put stores a key-value pair in this multimap, dropping or evicting key-value pairs to respect the limits.
+// A value rejected by the Reject policy is silently discarded, use TryPut to find out.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	m.TryPut(key, value)
+}
+
+// TryPut stores a key-value pair in this multimap, dropping or evicting key-value pairs to respect the limits.
+// It returns false if the key is full and the value was rejected by the Reject policy, otherwise true.
+func (m *MultiMap[K, V]) TryPut(key K, value V) bool {
+	b, found := m.m[key]
+	if limit := m.options.MaxValuesPerKey; found && limit > 0 && len(b.values) >= limit {
+		switch m.options.PerKeyPolicy {
+		case Reject:
+			return false
+		case DropNewest:
+			m.drop(b, len(b.values)-1)
+		default:
+			m.drop(b, 0)
+		}
+	}
+	if !found {
+		b = &bucket[K, V]{key: key}
+		m.m[key] = b
+		heap.Push(&m.order, b)
+	}
+	b.values = append(b.values, value)
+	m.size++
+	m.use(b)
+
+	for limit := m.options.MaxSize; limit > 0 && m.size > limit; {
+		if victim := m.order.victim(b); victim != nil {
+			m.evict(victim)
+		} else {
+			m.drop(b, 0)
+		}
+	}
+	return true
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+// The values are put one after another, so the limits may drop some of them again.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	for _, value := range values {
+		m.Put(key, value)
+	}
+}
+
+// use records a use of the key of b.
+func (m *MultiMap[K, V]) use(b *bucket[K, V]) {
+	m.tick++
+	b.tick = m.tick
+	b.uses++
+	heap.Fix(&m.order, b.index)
+}
+
+// drop removes the value at index i of the key of b to respect the limits, which must leave the key non-empty.
+func (m *MultiMap[K, V]) drop(b *bucket[K, V], i int) {
+	value := b.values[i]
+	b.values = slices.Delete(b.values, i, i+1)
+	m.size--
+	if m.options.OnEvict != nil {
+		m.options.OnEvict(b.key, value)
+	}
+}
+
+// evict removes all values of the key of b to respect the limits.
+func (m *MultiMap[K, V]) evict(b *bucket[K, V]) {
+	m.delete(b)
+	if m.options.OnEvict != nil {
+		for _, value := range b.values {
+			m.options.OnEvict(b.key, value)
+		}
+	}
+}
+
+// delete removes the key of b with all its values.
+func (m *MultiMap[K, V]) delete(b *bucket[K, V]) {
+	delete(m.m, b.key)
+	heap.Remove(&m.order, b.index)
+	m.size -= len(b.values)
+}
+
+// Contains returns true if this multimap contains at least one key-value pair with the key key and the value value.
+// It does not record a use of the key.
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	b, found := m.m[key]
+	return found && slices.Contains(b.values, value)
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+// It does not record a use of the key.
+func (m *MultiMap[K, V]) ContainsKey(key K) (found bool) {
+	_, found = m.m[key]
+	return
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	for _, b := range m.m {
+		if slices.Contains(b.values, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes a single key-value pair from this multimap, if such exists.
+// When the key holds the value several times, its first occurrence is removed.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	b, found := m.m[key]
+	if !found {
+		return
+	}
+	i := slices.Index(b.values, value)
+	switch {
+	case i < 0:
+		return
+	case len(b.values) == 1:
+		m.delete(b)
+	default:
+		b.values = slices.Delete(b.values, i, i+1)
+		m.size--
+	}
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	if b, found := m.m[key]; found {
+		m.delete(b)
+	}
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	return m.size == 0
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	return m.size
+}
+
+// Keys returns a view collection containing the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+func (m *MultiMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.size)
+	for key, b := range m.m {
+		for range b.values {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// KeySet returns all distinct keys contained in this multimap.
+func (m *MultiMap[K, V]) KeySet() []K {
+	keys := make([]K, 0, len(m.m))
+	for key := range m.m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Values returns all values from each key-value pair contained in this multimap.
+// This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
+func (m *MultiMap[K, V]) Values() []V {
+	values := make([]V, 0, m.size)
+	for _, b := range m.m {
+		values = append(values, b.values...)
+	}
+	return values
+}
+
+// Entries view collection of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances.
+// Retrieving the key and value from the entries result will be as trivial as:
+//   - var entry = m.Entries()[0]
+//   - var key = entry.Key
+//   - var value = entry.Value
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	entries := make([]multimap.Entry[K, V], 0, m.size)
+	for key, b := range m.m {
+		for _, value := range b.values {
+			entries = append(entries, multimap.Entry[K, V]{Key: key, Value: value})
+		}
+	}
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, b := range m.m {
+			for _, value := range b.values {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key, b := range m.m {
+			for range b.values {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, b := range m.m {
+			for _, value := range b.values {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with their values.
+// The yielded values are not copied and must not be modified.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for key, b := range m.m {
+			if !yield(key, b.values[:len(b.values):len(b.values)]) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.m = make(map[K]*bucket[K, V])
+	m.order.buckets = nil
+	m.size = 0
+}
+
+// Clone returns a copy of the multimap with the same limits, callback and usage of keys.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := &MultiMap[K, V]{m: make(map[K]*bucket[K, V], len(m.m)), size: m.size, options: m.options, tick: m.tick}
+	c.order = order[K, V]{eviction: m.order.eviction, buckets: make([]*bucket[K, V], len(m.order.buckets))}
+	for i, b := range m.order.buckets {
+		clone := *b
+		clone.values = slices.Clone(b.values)
+		c.order.buckets[i] = &clone
+		c.m[b.key] = &clone
+	}
+	return c
+}
+
+// order is a heap of buckets, the bucket of the key to evict first at its root.
+type order[K comparable, V comparable] struct {
+	eviction Eviction
+	buckets  []*bucket[K, V]
+}
+
+// victim returns the key to evict first other than the key of b, or nil if there is none.
+// The second key in a heap is one of the children of its root.
+func (o *order[K, V]) victim(b *bucket[K, V]) *bucket[K, V] {
+	switch {
+	case len(o.buckets) == 0:
+		return nil
+	case o.buckets[0] != b:
+		return o.buckets[0]
+	case len(o.buckets) == 1:
+		return nil
+	case len(o.buckets) == 2 || o.Less(1, 2):
+		return o.buckets[1]
+	default:
+		return o.buckets[2]
+	}
+}
+
+func (o *order[K, V]) Len() int {
+	return len(o.buckets)
+}
+
+func (o *order[K, V]) Less(i, j int) bool {
+	a, b := o.buckets[i], o.buckets[j]
+	if o.eviction == LFU && a.uses != b.uses {
+		return a.uses < b.uses
+	}
+	return a.tick < b.tick
+}
+
+func (o *order[K, V]) Swap(i, j int) {
+	o.buckets[i], o.buckets[j] = o.buckets[j], o.buckets[i]
+	o.buckets[i].index = i
+	o.buckets[j].index = j
+}
+
+func (o *order[K, V]) Push(x any) {
+	b := x.(*bucket[K, V])
+	b.index = len(o.buckets)
+	o.buckets = append(o.buckets, b)
+}
+
+func (o *order[K, V]) Pop() any {
+	n := len(o.buckets) - 1
+	b := o.buckets[n]
+	o.buckets[n] = nil
+	o.buckets = o.buckets[:n]
+	return b
+}
@@ -0,0 +1,440 @@
+package boundedmultimap
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
+	"github.com/rafos/go-multimap/slicemultimap"
+	"github.com/rafos/go-multimap/syncmultimap"
+)
+
+// evictions records the key-value pairs reported to OnEvict.
+type evictions struct {
+	entries []multimap.Entry[string, int]
+}
+
+func (e *evictions) record(key string, value int) {
+	e.entries = append(e.entries, multimap.Entry[string, int]{Key: key, Value: value})
+}
+
+func TestPerKeyPolicies(t *testing.T) {
+	tests := []struct {
+		policy   PerKeyPolicy
+		expected []int
+		evicted  []multimap.Entry[string, int]
+		stored   []bool
+	}{
+		{DropOldest, []int{3, 4}, []multimap.Entry[string, int]{{Key: "a", Value: 1}, {Key: "a", Value: 2}}, []bool{true, true, true, true}},
+		{DropNewest, []int{1, 4}, []multimap.Entry[string, int]{{Key: "a", Value: 2}, {Key: "a", Value: 3}}, []bool{true, true, true, true}},
+		{Reject, []int{1, 2}, nil, []bool{true, true, false, false}},
+	}
+
+	for _, test := range tests {
+		var e evictions
+		m := New(Options[string, int]{MaxValuesPerKey: 2, PerKeyPolicy: test.policy, OnEvict: e.record})
+		var stored []bool
+		for value := 1; value <= 4; value++ {
+			stored = append(stored, m.TryPut("a", value))
+		}
+		m.Put("b", 5)
+
+		if actualValue, _ := m.Get("a"); !slices.Equal(actualValue, test.expected) {
+			t.Errorf("policy %d: expected %v, got %v", test.policy, test.expected, actualValue)
+		}
+		if !slices.Equal(stored, test.stored) {
+			t.Errorf("policy %d: expected %v, got %v", test.policy, test.stored, stored)
+		}
+		if !slices.Equal(e.entries, test.evicted) {
+			t.Errorf("policy %d: expected %v, got %v", test.policy, test.evicted, e.entries)
+		}
+		if actualValue := m.Size(); actualValue != 3 {
+			t.Errorf("policy %d: expected %v, got %v", test.policy, 3, actualValue)
+		}
+	}
+}
+
+func TestLRU(t *testing.T) {
+	var e evictions
+	m := New(Options[string, int]{MaxSize: 4, Eviction: LRU, OnEvict: e.record})
+	m.PutAll("a", []int{1, 2})
+	m.Put("b", 3)
+	m.Put("c", 4)
+
+	// Get uses a, so b becomes the least recently used key.
+	m.Get("a")
+	m.Put("d", 5)
+	if m.ContainsKey("b") || !m.ContainsKey("a") {
+		t.Errorf("expected b to be evicted, got %v", m.KeySet())
+	}
+
+	// Contains does not use c.
+	m.Contains("c", 4)
+	m.Put("e", 6)
+	if m.ContainsKey("c") {
+		t.Errorf("expected c to be evicted, got %v", m.KeySet())
+	}
+
+	// Evicting a key evicts all its values.
+	m.Put("d", 7)
+	if m.ContainsKey("a") {
+		t.Errorf("expected a to be evicted, got %v", m.KeySet())
+	}
+	expected := []multimap.Entry[string, int]{{Key: "b", Value: 3}, {Key: "c", Value: 4}, {Key: "a", Value: 1}, {Key: "a", Value: 2}}
+	if !slices.Equal(e.entries, expected) {
+		t.Errorf("expected %v, got %v", expected, e.entries)
+	}
+	if actualValue := m.Size(); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+}
+
+func TestReadsDoNotUse(t *testing.T) {
+	other := slicemultimap.New[string, int]()
+	other.Put("x", 1)
+	other.Put("y", 2)
+
+	for i := 0; i < 20; i++ {
+		m := New(Options[string, int]{MaxSize: 2, Eviction: LRU})
+		m.Put("x", 1)
+		m.Put("y", 2)
+
+		// None of these may use x, which stays the least recently used key.
+		m.Peek("x")
+		m.Contains("x", 1)
+		multimap.Equal[string, int](other, m)
+		multimap.EqualUnordered[string, int](other, m)
+		multimap.Union(other, m, multimap.Bag, slicemultimap.New[string, int])
+		multimap.Intersection(other, m, multimap.Set, slicemultimap.New[string, int])
+		multimap.Difference(other, m, multimap.Bag, slicemultimap.New[string, int])
+		multimap.SymmetricDifference(other, m, multimap.Set, slicemultimap.New[string, int])
+
+		m.Put("z", 3)
+		if actualValue, expectedValue := m.KeySet(), []string{"y", "z"}; !sameElements(actualValue, expectedValue) {
+			t.Fatalf("run %d: expected %v, got %v", i, expectedValue, actualValue)
+		}
+	}
+
+	m := New(Options[string, int]{})
+	m.PutAll("a", []int{1, 2})
+	if actualValue, found := m.Peek("a"); !found || !slices.Equal(actualValue, []int{1, 2}) {
+		t.Errorf("expected %v, got %v, %v", []int{1, 2}, actualValue, found)
+	}
+	if actualValue, found := m.Peek("b"); found || actualValue != nil {
+		t.Errorf("expected %v, got %v, %v", nil, actualValue, found)
+	}
+}
+
+func TestLFU(t *testing.T) {
+	var e evictions
+	m := New(Options[string, int]{MaxSize: 3, Eviction: LFU, OnEvict: e.record})
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+	m.Get("a")
+	m.Get("a")
+	m.Get("c")
+
+	// b is the least frequently used key, the new key d is never evicted itself.
+	m.Put("d", 4)
+	if actualValue, expectedValue := m.KeySet(), []string{"a", "c", "d"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	// c and d have been used twice and once, d is evicted although it is the most recent key.
+	m.Put("e", 5)
+	if actualValue, expectedValue := m.KeySet(), []string{"a", "c", "e"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	// Among equally used keys, the least recently used one is evicted.
+	m.Get("e")
+	m.Put("f", 6)
+	if actualValue, expectedValue := m.KeySet(), []string{"a", "e", "f"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	expected := []multimap.Entry[string, int]{{Key: "b", Value: 2}, {Key: "d", Value: 4}, {Key: "c", Value: 3}}
+	if !slices.Equal(e.entries, expected) {
+		t.Errorf("expected %v, got %v", expected, e.entries)
+	}
+}
+
+func TestOnlyKeyOverflow(t *testing.T) {
+	var e evictions
+	m := New(Options[string, int]{MaxValuesPerKey: 10, PerKeyPolicy: Reject, MaxSize: 3, OnEvict: e.record})
+	m.PutAll("a", []int{1, 2, 3, 4, 5})
+
+	if actualValue, _ := m.Get("a"); !slices.Equal(actualValue, []int{3, 4, 5}) {
+		t.Errorf("expected %v, got %v", []int{3, 4, 5}, actualValue)
+	}
+	if expected := []multimap.Entry[string, int]{{Key: "a", Value: 1}, {Key: "a", Value: 2}}; !slices.Equal(e.entries, expected) {
+		t.Errorf("expected %v, got %v", expected, e.entries)
+	}
+}
+
+func TestRemoveIsNotEviction(t *testing.T) {
+	var e evictions
+	m := New(Options[string, int]{MaxSize: 2, OnEvict: e.record})
+	m.PutAll("a", []int{1, 2})
+	m.Remove("a", 1)
+	m.Remove("a", 2)
+	m.Put("b", 3)
+	m.RemoveAll("b")
+	m.Put("c", 4)
+	m.Clear()
+
+	if len(e.entries) != 0 {
+		t.Errorf("expected no evictions, got %v", e.entries)
+	}
+	if !m.Empty() {
+		t.Errorf("expected an empty multimap, got %v", m.Entries())
+	}
+
+	m.PutAll("d", []int{5, 6})
+	m.Put("e", 7)
+	if expected := []multimap.Entry[string, int]{{Key: "d", Value: 5}, {Key: "d", Value: 6}}; !slices.Equal(e.entries, expected) {
+		t.Errorf("expected %v, got %v", expected, e.entries)
+	}
+}
+
+func TestNegativeLimit(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected a panic for a negative limit")
+		}
+	}()
+	New(Options[string, int]{MaxSize: -1})
+}
+
+func TestRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, eviction := range []Eviction{LRU, LFU} {
+		for _, policy := range []PerKeyPolicy{DropOldest, DropNewest, Reject} {
+			evicted := 0
+			m := New(Options[int, int]{
+				MaxValuesPerKey: 3, PerKeyPolicy: policy,
+				MaxSize: 10, Eviction: eviction,
+				OnEvict: func(int, int) { evicted++ },
+			})
+			put, removed := 0, 0
+			for i := 0; i < 2000; i++ {
+				key, value := r.Intn(8), r.Intn(4)
+				switch r.Intn(6) {
+				case 0:
+					if m.Contains(key, value) {
+						removed++
+					}
+					m.Remove(key, value)
+				case 1:
+					values, _ := m.Get(key)
+					removed += len(values)
+					m.RemoveAll(key)
+				case 2:
+					m.Get(key)
+				default:
+					if m.TryPut(key, value) {
+						put++
+					}
+				}
+				checkInvariants(t, m)
+			}
+			if actualValue, expectedValue := m.Size(), put-removed-evicted; actualValue != expectedValue {
+				t.Errorf("eviction %d, policy %d: expected %v, got %v", eviction, policy, expectedValue, actualValue)
+			}
+		}
+	}
+}
+
+// checkInvariants checks the limits of the multimap and the consistency of its heap.
+func checkInvariants[K comparable, V comparable](t *testing.T, m *MultiMap[K, V]) {
+	t.Helper()
+	size := 0
+	for key, b := range m.m {
+		size += len(b.values)
+		if len(b.values) == 0 || (m.options.MaxValuesPerKey > 0 && len(b.values) > m.options.MaxValuesPerKey) {
+			t.Fatalf("key %v: unexpected number of values %v", key, len(b.values))
+		}
+		if b.index >= len(m.order.buckets) || m.order.buckets[b.index] != b {
+			t.Fatalf("key %v: heap index %v out of sync", key, b.index)
+		}
+	}
+	if size != m.size || (m.options.MaxSize > 0 && size > m.options.MaxSize) {
+		t.Fatalf("unexpected size %v, counted %v", m.size, size)
+	}
+	if len(m.order.buckets) != len(m.m) {
+		t.Fatalf("expected %v keys in heap, got %v", len(m.m), len(m.order.buckets))
+	}
+	for i := 1; i < len(m.order.buckets); i++ {
+		if m.order.Less(i, (i-1)/2) {
+			t.Fatalf("heap order violated at %v", i)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := New(Options[string, int]{MaxSize: 3})
+	m.PutAll("a", []int{1, 2})
+	m.Put("b", 3)
+
+	c := m.Clone()
+	if !multimap.Equal[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+
+	// The clone keeps the usage of keys, a is evicted from both.
+	c.Put("c", 4)
+	m.Put("c", 4)
+	if c.ContainsKey("a") || m.ContainsKey("a") {
+		t.Errorf("expected a to be evicted, got %v and %v", m.KeySet(), c.KeySet())
+	}
+	c.Put("d", 5)
+	if !m.ContainsKey("b") || m.ContainsKey("d") {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+	checkInvariants(t, m)
+	checkInvariants(t, c)
+}
+
+func TestConcurrentGet(t *testing.T) {
+	m := syncmultimap.New[int, int](New(Options[int, int]{MaxSize: 100, Eviction: LFU}))
+	for i := 0; i < 100; i++ {
+		m.Put(i%10, i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Get(i % 10)
+			}
+		}()
+	}
+	wg.Wait()
+	if actualValue := m.Size(); actualValue != 100 {
+		t.Errorf("expected %v, got %v", 100, actualValue)
+	}
+}
+
+func TestConcurrentClone(t *testing.T) {
+	b := New(Options[int, int]{MaxSize: 100, Eviction: LRU})
+	m := syncmultimap.New[int, int](b)
+	for i := 0; i < 100; i++ {
+		m.Put(i%10, i)
+	}
+
+	// Get, Clone and Clone through the wrapper are all readers, which may run concurrently.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				b.Get(i % 10)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if c := b.Clone(); len(c.order.buckets) != 10 || c.Size() != 100 {
+					t.Errorf("expected %v keys and %v pairs, got %v and %v", 10, 100, len(c.order.buckets), c.Size())
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				c := m.Clone(func() multimap.MultiMap[int, int] {
+					return New(Options[int, int]{MaxSize: 100, Eviction: LRU})
+				})
+				if actualValue := c.Size(); actualValue != 100 {
+					t.Errorf("expected %v, got %v", 100, actualValue)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return New(Options[string, string]{})
+	}, multimaptest.List)
+}
+
+func TestModel(t *testing.T) {
+	multimaptest.RunModel(t, func() multimap.MultiMap[string, string] {
+		return New(Options[string, string]{MaxValuesPerKey: 1000, MaxSize: 1000})
+	}, multimaptest.List)
+}
+
+func TestIterators(t *testing.T) {
+	m := New(Options[string, int]{MaxValuesPerKey: 2})
+	m.PutAll("a", []int{1, 2, 3})
+	m.Put("b", 4)
+
+	var entries []multimap.Entry[string, int]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[string, int]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := slices.Collect(m.KeysSeq()), []string{"a", "a", "b"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := slices.Collect(m.ValuesSeq()), []int{2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	for key, values := range m.Sets() {
+		if expectedValue, _ := m.Get(key); !slices.Equal(values, expectedValue) {
+			t.Errorf("key %s: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, m *MultiMap[int, int], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Put(n%(size/10+1), n)
+		}
+	}
+}
+
+func BenchmarkMultiMapPutLRU10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New(Options[int, int]{MaxValuesPerKey: 16, MaxSize: size / 2, Eviction: LRU})
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPutLFU10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New(Options[int, int]{MaxValuesPerKey: 16, MaxSize: size / 2, Eviction: LFU})
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[V]int)
+	for _, value := range a {
+		counts[value]++
+	}
+	for _, value := range b {
+		if counts[value] == 0 {
+			return false
+		}
+		counts[value]--
+	}
+	return true
+}
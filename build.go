@@ -0,0 +1,25 @@
+package multimap
+
+import "iter"
+
+// Index returns a new multimap, created by newMultiMap, holding every item of items
+// under the key computed by key.
+//
+// This is typically known as Multimaps.index in other languages.
+func Index[T any, K comparable, M MultiMap[K, T]](items iter.Seq[T], key func(T) K, newMultiMap func() M) M {
+	dst := newMultiMap()
+	for item := range items {
+		dst.Put(key(item), item)
+	}
+	return dst
+}
+
+// GroupBy returns a new multimap, created by newMultiMap, holding the value computed by value
+// for every item of items, under the key computed by key.
+func GroupBy[T any, K comparable, V any, M MultiMap[K, V]](items iter.Seq[T], key func(T) K, value func(T) V, newMultiMap func() M) M {
+	dst := newMultiMap()
+	for item := range items {
+		dst.Put(key(item), value(item))
+	}
+	return dst
+}
@@ -0,0 +1,53 @@
+package multimap_test
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/eqmultimap"
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+	"github.com/rafos/go-multimap/treemultimap"
+)
+
+func TestIndex(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "apple"}
+
+	m := multimap.Index(slices.Values(words), func(word string) byte { return word[0] }, treemultimap.NewOrdered[byte, string])
+	if actualValue, _ := m.Get('a'); !sameOrder(actualValue, []string{"apple", "avocado", "apple"}) {
+		t.Errorf("expected %v, got %v", []string{"apple", "avocado", "apple"}, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []byte{'a', 'b'}; !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	distinct := multimap.Index(slices.Values(words), func(word string) byte { return word[0] }, setmultimap.New[byte, string])
+	if actualValue := distinct.Size(); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+
+	// Items that are not comparable can be indexed into an eqmultimap.
+	routes := [][]string{{"api", "users"}, {"api", "orders"}, {"static", "css"}}
+	byRoot := multimap.Index(slices.Values(routes), func(route []string) string { return route[0] }, func() *eqmultimap.MultiMap[string, []string] {
+		return eqmultimap.New[string](slices.Equal[[]string])
+	})
+	if actualValue, _ := byRoot.Get("api"); len(actualValue) != 2 || !byRoot.Contains("api", []string{"api", "orders"}) {
+		t.Errorf("expected %v, got %v", routes[:2], actualValue)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	ages := map[string]int{"ann": 31, "bob": 45, "cid": 38, "dan": 52}
+
+	m := multimap.GroupBy(maps.Keys(ages), func(name string) int { return ages[name] / 10 * 10 }, strings.ToUpper, slicemultimap.New[int, string])
+	expected := slicemultimap.New[int, string]()
+	expected.PutAll(30, []string{"ANN", "CID"})
+	expected.Put(40, "BOB")
+	expected.Put(50, "DAN")
+	if !multimap.EqualUnordered[int, string](m, expected) {
+		t.Errorf("expected %v, got %v", expected.Entries(), m.Entries())
+	}
+}
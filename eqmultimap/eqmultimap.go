@@ -0,0 +1,290 @@
+// Package eqmultimap implements a multimap whose values are compared by a custom equality function.
+//
+// An eqmultimap is a multimap that can hold duplicate key-value pairs
+// and that maintains the insertion ordering of values for a given key, like a slicemultimap.
+// Values do not have to be comparable: Contains, ContainsValue and Remove match values
+// with the equality function given to the constructor instead of ==, so values can be
+// slices, maps, or structs whose equality ignores some of their fields.
+//
+// ContainsValue has to compare the value to every value of the multimap, unless the multimap
+// is created by NewHashed with a hash function consistent with the equality function.
+//
+// Elements are unordered in the map.
+//
+// Structure is not thread safe.
+package eqmultimap
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/rafos/go-multimap"
+)
+
+var _ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+
+// MultiMap holds the elements in go's native map of slices.
+type MultiMap[K comparable, V any] struct {
+	m     map[K][]V
+	size  int
+	equal func(a, b V) bool
+
+	// hash and byHash are only set by NewHashed.
+	// byHash holds every value of the multimap, indexed by its hash.
+	hash   func(V) uint64
+	byHash map[uint64][]V
+}
+
+// New instantiates a new multimap comparing values with equal.
+func New[K comparable, V any](equal func(a, b V) bool) *MultiMap[K, V] {
+	return &MultiMap[K, V]{m: make(map[K][]V), equal: equal}
+}
+
+// NewHashed instantiates a new multimap comparing values with equal, which also indexes
+// all values by hash to make ContainsValue independent of the number of keys.
+// Values that are equal must have the same hash.
+func NewHashed[K comparable, V any](equal func(a, b V) bool, hash func(V) uint64) *MultiMap[K, V] {
+	m := New[K, V](equal)
+	m.hash = hash
+	m.byHash = make(map[uint64][]V)
+	return m
+}
+
+// Get searches the element in the multimap by key.
+// It returns a copy of its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	values, found = m.m[key]
+	return slices.Clone(values), found
+}
+
+// Put stores a key-value pair in this multimap.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	m.m[key] = append(m.m[key], value)
+	m.size++
+	if m.byHash != nil {
+		h := m.hash(value)
+		m.byHash[h] = append(m.byHash[h], value)
+	}
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	for _, value := range values {
+		m.Put(key, value)
+	}
+}
+
+// Contains returns true if this multimap contains at least one key-value pair with the key key
+// and a value equal to value.
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	return m.index(m.m[key], value) >= 0
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) (found bool) {
+	_, found = m.m[key]
+	return
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with a value equal to value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	if m.byHash != nil {
+		return m.index(m.byHash[m.hash(value)], value) >= 0
+	}
+	for _, values := range m.m {
+		if m.index(values, value) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes a single key-value pair with a value equal to value from this multimap, if such exists.
+// When the key holds several equal values, the first one is removed.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	values := m.m[key]
+	i := m.index(values, value)
+	if i < 0 {
+		return
+	}
+	m.size--
+	if m.byHash != nil {
+		m.unindex(values[i])
+	}
+	if len(values) == 1 {
+		delete(m.m, key)
+		return
+	}
+	m.m[key] = append(values[:i:i], values[i+1:]...)
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	if m.byHash != nil {
+		for _, value := range m.m[key] {
+			m.unindex(value)
+		}
+	}
+	m.size -= len(m.m[key])
+	delete(m.m, key)
+}
+
+// index returns the index of the first of values equal to value, or -1 if there is none.
+func (m *MultiMap[K, V]) index(values []V, value V) int {
+	return slices.IndexFunc(values, func(v V) bool { return m.equal(v, value) })
+}
+
+// unindex removes one value equal to value from the hash index.
+func (m *MultiMap[K, V]) unindex(value V) {
+	h := m.hash(value)
+	bucket := m.byHash[h]
+	i := m.index(bucket, value)
+	if i < 0 {
+		return
+	}
+	if len(bucket) == 1 {
+		delete(m.byHash, h)
+		return
+	}
+	m.byHash[h] = slices.Delete(bucket, i, i+1)
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	return m.size == 0
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	return m.size
+}
+
+// Keys returns a view collection containing the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+func (m *MultiMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.size)
+	for key, values := range m.m {
+		for range values {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// KeySet returns all distinct keys contained in this multimap.
+func (m *MultiMap[K, V]) KeySet() []K {
+	keys := make([]K, 0, len(m.m))
+	for key := range m.m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Values returns all values from each key-value pair contained in this multimap.
+// This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
+func (m *MultiMap[K, V]) Values() []V {
+	values := make([]V, 0, m.size)
+	for _, vs := range m.m {
+		values = append(values, vs...)
+	}
+	return values
+}
+
+// Entries view collection of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances.
+// Retrieving the key and value from the entries result will be as trivial as:
+//   - var entry = m.Entries()[0]
+//   - var key = entry.Key
+//   - var value = entry.Value
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	entries := make([]multimap.Entry[K, V], 0, m.size)
+	for key, values := range m.m {
+		for _, value := range values {
+			entries = append(entries, multimap.Entry[K, V]{Key: key, Value: value})
+		}
+	}
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, values := range m.m {
+			for _, value := range values {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key, values := range m.m {
+			for range values {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, values := range m.m {
+			for _, value := range values {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with their values.
+// The yielded values are not copied and must not be modified.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for key, values := range m.m {
+			if !yield(key, values[:len(values):len(values)]) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.m = make(map[K][]V)
+	m.size = 0
+	if m.byHash != nil {
+		m.byHash = make(map[uint64][]V)
+	}
+}
+
+// Clone returns a copy of the multimap using the same equality and hash functions.
+// The slices of values are copied, the values themselves are not.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	c := &MultiMap[K, V]{m: make(map[K][]V, len(m.m)), size: m.size, equal: m.equal, hash: m.hash}
+	for key, values := range m.m {
+		c.m[key] = slices.Clone(values)
+	}
+	if m.byHash != nil {
+		c.byHash = make(map[uint64][]V, len(m.byHash))
+		for h, values := range m.byHash {
+			c.byHash[h] = slices.Clone(values)
+		}
+	}
+	return c
+}
@@ -0,0 +1,232 @@
+package eqmultimap
+
+import (
+	"hash/maphash"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
+)
+
+var seed = maphash.MakeSeed()
+
+// foldHash hashes a string consistently with strings.EqualFold for ASCII strings.
+func foldHash(s string) uint64 {
+	return maphash.String(seed, strings.ToLower(s))
+}
+
+// constructors returns a plain and a hashed multimap of case-insensitive strings.
+func constructors() map[string]func() *MultiMap[int, string] {
+	return map[string]func() *MultiMap[int, string]{
+		"plain":  func() *MultiMap[int, string] { return New[int](strings.EqualFold) },
+		"hashed": func() *MultiMap[int, string] { return NewHashed[int](strings.EqualFold, foldHash) },
+	}
+}
+
+func TestSliceValues(t *testing.T) {
+	m := New[string](slices.Equal[[]int])
+	m.Put("a", []int{1, 2})
+	m.Put("a", []int{3})
+	m.Put("b", []int{1, 2})
+	m.Put("b", nil)
+
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.Contains("a", []int{1, 2}) || m.Contains("a", []int{1}) {
+		t.Errorf("expected Contains to compare the elements of the slices")
+	}
+	if !m.ContainsValue([]int{3}) || !m.ContainsValue([]int{}) || m.ContainsValue([]int{2, 1}) {
+		t.Errorf("expected ContainsValue to compare the elements of the slices")
+	}
+
+	m.Remove("b", []int{})
+	if actualValue, _ := m.Get("b"); len(actualValue) != 1 || !slices.Equal(actualValue[0], []int{1, 2}) {
+		t.Errorf("expected %v, got %v", [][]int{{1, 2}}, actualValue)
+	}
+	m.Remove("a", []int{1, 2})
+	if actualValue, _ := m.Get("a"); len(actualValue) != 1 || !slices.Equal(actualValue[0], []int{3}) {
+		t.Errorf("expected %v, got %v", [][]int{{3}}, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+}
+
+func TestStructValues(t *testing.T) {
+	type user struct {
+		id    int
+		name  string
+		token []byte
+	}
+	m := New[string](func(a, b user) bool { return a.id == b.id })
+	m.Put("admins", user{1, "alice", []byte("secret")})
+	m.Put("admins", user{2, "bob", nil})
+
+	if !m.Contains("admins", user{id: 1}) {
+		t.Errorf("expected %v, got %v", true, false)
+	}
+	m.Remove("admins", user{id: 2, name: "robert"})
+	if actualValue, _ := m.Get("admins"); len(actualValue) != 1 || actualValue[0].name != "alice" {
+		t.Errorf("expected %v, got %v", "alice", actualValue)
+	}
+}
+
+func TestEqualFold(t *testing.T) {
+	for name, newMultiMap := range constructors() {
+		m := newMultiMap()
+		m.PutAll(1, []string{"Go", "rust", "GO"})
+		m.Put(2, "Zig")
+
+		if actualValue := m.Size(); actualValue != 4 {
+			t.Errorf("%s: expected %v, got %v", name, 4, actualValue)
+		}
+		if !m.Contains(1, "go") || !m.Contains(1, "RUST") || m.Contains(2, "go") {
+			t.Errorf("%s: expected Contains to ignore case", name)
+		}
+		if !m.ContainsValue("zig") || !m.ContainsValue("gO") || m.ContainsValue("c") {
+			t.Errorf("%s: expected ContainsValue to ignore case", name)
+		}
+
+		// Remove removes the first equal value, keeping the spelling of the others.
+		m.Remove(1, "go")
+		if actualValue, expectedValue := m.Values(), []string{"rust", "GO", "Zig"}; !sameElements(actualValue, expectedValue) {
+			t.Errorf("%s: expected %v, got %v", name, expectedValue, actualValue)
+		}
+		m.Remove(1, "Go")
+		if m.ContainsValue("go") {
+			t.Errorf("%s: expected %v, got %v", name, false, true)
+		}
+		m.RemoveAll(2)
+		if m.ContainsValue("zig") {
+			t.Errorf("%s: expected %v, got %v", name, false, true)
+		}
+		if actualValue, _ := m.Get(1); !slices.Equal(actualValue, []string{"rust"}) {
+			t.Errorf("%s: expected %v, got %v", name, []string{"rust"}, actualValue)
+		}
+
+		m.Clear()
+		if m.ContainsValue("rust") || !m.Empty() {
+			t.Errorf("%s: expected an empty multimap, got %v", name, m.Entries())
+		}
+	}
+}
+
+func TestHashedIndex(t *testing.T) {
+	m := NewHashed[int](strings.EqualFold, foldHash)
+	m.PutAll(1, []string{"a", "A", "b"})
+	m.PutAll(2, []string{"a"})
+
+	if actualValue := len(m.byHash[foldHash("a")]); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+	m.Remove(1, "a")
+	m.RemoveAll(2)
+	if actualValue := len(m.byHash[foldHash("a")]); actualValue != 1 {
+		t.Errorf("expected %v, got %v", 1, actualValue)
+	}
+	m.Remove(1, "B")
+	if _, found := m.byHash[foldHash("b")]; found {
+		t.Errorf("expected %v, got %v", false, found)
+	}
+
+	c := m.Clone()
+	c.Remove(1, "a")
+	if !m.ContainsValue("a") || c.ContainsValue("a") {
+		t.Errorf("expected the index of the multimap to be unaffected by changes of its clone")
+	}
+}
+
+func TestClone(t *testing.T) {
+	for name, newMultiMap := range constructors() {
+		m := newMultiMap()
+		m.PutAll(1, []string{"c", "a", "b"})
+		m.Put(2, "d")
+
+		c := m.Clone()
+		if !multimap.Equal[int, string](m, c) {
+			t.Errorf("%s: expected %v, got %v", name, m.Entries(), c.Entries())
+		}
+
+		c.Put(1, "e")
+		c.Remove(2, "D")
+		if actualValue := m.Size(); actualValue != 4 {
+			t.Errorf("%s: expected %v, got %v", name, 4, actualValue)
+		}
+		if !m.Contains(2, "d") || m.Contains(1, "e") {
+			t.Errorf("%s: expected the multimap to be unaffected by changes of its clone", name)
+		}
+		if !c.Contains(1, "E") {
+			t.Errorf("%s: expected the clone to keep the equality function", name)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	equal := func(a, b string) bool { return a == b }
+	t.Run("plain", func(t *testing.T) {
+		multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+			return New[string](equal)
+		}, multimaptest.List)
+	})
+	t.Run("hashed", func(t *testing.T) {
+		multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+			return NewHashed[string](equal, func(s string) uint64 { return maphash.String(seed, s) })
+		}, multimaptest.List)
+	})
+}
+
+func TestModel(t *testing.T) {
+	multimaptest.RunModel(t, func() multimap.MultiMap[string, string] {
+		return NewHashed[string](func(a, b string) bool { return a == b }, func(s string) uint64 { return uint64(len(s)) })
+	}, multimaptest.List)
+}
+
+func TestIterators(t *testing.T) {
+	m := New[int](slices.Equal[[]int])
+	m.Put(1, []int{1})
+	m.Put(1, []int{2})
+	m.Put(2, []int{3})
+
+	count := 0
+	for key, value := range m.All() {
+		if !m.Contains(key, value) {
+			t.Errorf("expected %v, got %v", true, false)
+		}
+		count++
+	}
+	if count != 3 {
+		t.Errorf("expected %v, got %v", 3, count)
+	}
+	for key, values := range m.Sets() {
+		if expectedValue, _ := m.Get(key); !slices.EqualFunc(values, expectedValue, slices.Equal[[]int]) {
+			t.Errorf("key %d: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+	if actualValue := len(slices.Collect(m.ValuesSeq())); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+	if actualValue, expectedValue := slices.Collect(m.KeysSeq()), m.Keys(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[V]int)
+	for _, value := range a {
+		counts[value]++
+	}
+	for _, value := range b {
+		if counts[value] == 0 {
+			return false
+		}
+		counts[value]--
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package multimap
+
+import "hash/maphash"
+
+// seed is the seed of the hashes computed by Hash.
+var seed = maphash.MakeSeed()
+
+// Equal reports whether the multimaps a and b hold the same keys, each with the same values in the same order.
+// This is the equality of list multimaps, which keep the insertion ordering of values for a given key.
+func Equal[K comparable, V comparable](a, b MultiMap[K, V]) bool {
+	return equal(a, b, func(x, y []V) bool {
+		for i := range x {
+			if x[i] != y[i] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// EqualUnordered reports whether the multimaps a and b hold the same keys, each with the same values
+// the same number of times, regardless of their order.
+// This is the equality of set multimaps, whose values for a given key are unordered.
+func EqualUnordered[K comparable, V comparable](a, b MultiMap[K, V]) bool {
+	return equal(a, b, func(x, y []V) bool {
+		counts := make(map[V]int, len(x))
+		for _, value := range x {
+			counts[value]++
+		}
+		for _, value := range y {
+			if counts[value] == 0 {
+				return false
+			}
+			counts[value]--
+		}
+		return true
+	})
+}
+
+// equal reports whether a and b hold the same keys with values of the same length for which same returns true.
+func equal[K comparable, V comparable](a, b MultiMap[K, V], same func(x, y []V) bool) bool {
+	if a.Size() != b.Size() {
+		return false
+	}
+	bSets := sets(b)
+	for key, x := range a.Sets() {
+		y, found := bSets[key]
+		if !found || len(x) != len(y) || !same(x, y) {
+			return false
+		}
+	}
+	// Both multimaps have the same size and every key of a holds as many values in b,
+	// so b cannot hold any other key.
+	return true
+}
+
+// sets returns the values of every key of m, without copying them.
+// Unlike Get, Sets does not count as a use of the keys in multimaps tracking them, like boundedmultimap.
+func sets[K comparable, V any](m MultiMap[K, V]) map[K][]V {
+	result := make(map[K][]V)
+	for key, values := range m.Sets() {
+		result[key] = values
+	}
+	return result
+}
+
+// Hash returns a hash of the key-value pairs contained in the multimap m.
+// The hash does not depend on the order of keys or values, so multimaps for which EqualUnordered
+// (and therefore Equal) reports true have the same hash.
+// Hashes are only comparable within a single process.
+func Hash[K comparable, V comparable](m MultiMap[K, V]) uint64 {
+	var h uint64
+	for key, value := range m.All() {
+		// Adding up the hashes of all entries makes the result independent of the iteration order
+		// and keeps duplicate entries from cancelling each other out.
+		h += maphash.Comparable(seed, Entry[K, V]{Key: key, Value: value})
+	}
+	return h
+}
@@ -0,0 +1,156 @@
+package multimap_test
+
+import (
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/bimultimap"
+	"github.com/rafos/go-multimap/linkedmultimap"
+	"github.com/rafos/go-multimap/linkedsetmultimap"
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/shardedmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+	"github.com/rafos/go-multimap/syncmultimap"
+	"github.com/rafos/go-multimap/treemultimap"
+)
+
+// implementations returns a constructor for every multimap of this module,
+// together with whether it holds duplicate key-value pairs and whether it keeps the ordering of values for a given key.
+func implementations() map[string]struct {
+	new        func() multimap.MultiMap[string, int]
+	duplicates bool
+	ordered    bool
+} {
+	return map[string]struct {
+		new        func() multimap.MultiMap[string, int]
+		duplicates bool
+		ordered    bool
+	}{
+		"slicemultimap":     {func() multimap.MultiMap[string, int] { return slicemultimap.New[string, int]() }, true, true},
+		"setmultimap":       {func() multimap.MultiMap[string, int] { return setmultimap.New[string, int]() }, false, false},
+		"linkedsetmultimap": {func() multimap.MultiMap[string, int] { return linkedsetmultimap.New[string, int]() }, false, true},
+		"linkedmultimap":    {func() multimap.MultiMap[string, int] { return linkedmultimap.New[string, int]() }, true, true},
+		"treemultimap":      {func() multimap.MultiMap[string, int] { return treemultimap.NewOrdered[string, int]() }, true, true},
+		"bimultimap":        {func() multimap.MultiMap[string, int] { return bimultimap.New[string, int]() }, false, false},
+		"syncmultimap": {func() multimap.MultiMap[string, int] {
+			return syncmultimap.New[string, int](slicemultimap.New[string, int]())
+		}, true, true},
+		"shardedmultimap": {func() multimap.MultiMap[string, int] {
+			return shardedmultimap.New[string, int](4, linkedmultimap.New[string, int])
+		}, true, true},
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for name, impl := range implementations() {
+		a, b := impl.new(), impl.new()
+		if !multimap.Equal(a, b) || !multimap.EqualUnordered(a, b) {
+			t.Errorf("%s: expected empty multimaps to be equal", name)
+		}
+
+		a.PutAll("a", []int{1, 2, 3})
+		a.Put("b", 4)
+		b.Put("b", 4)
+		b.PutAll("a", []int{1, 2, 3})
+		if impl.ordered && (!multimap.Equal(a, b) || !multimap.Equal(b, a)) {
+			t.Errorf("%s: expected %v, got %v", name, a.Entries(), b.Entries())
+		}
+		if !multimap.EqualUnordered(a, b) {
+			t.Errorf("%s: expected %v, got %v", name, a.Entries(), b.Entries())
+		}
+
+		b.Put("c", 5)
+		if multimap.Equal(a, b) || multimap.Equal(b, a) || multimap.EqualUnordered(a, b) {
+			t.Errorf("%s: expected multimaps of different keys to differ", name)
+		}
+		b.RemoveAll("c")
+
+		b.Remove("b", 4)
+		b.Put("c", 4)
+		if multimap.Equal(a, b) || multimap.EqualUnordered(a, b) {
+			t.Errorf("%s: expected multimaps of different keys of the same size to differ", name)
+		}
+		b.RemoveAll("c")
+		b.Put("b", 5)
+		if multimap.Equal(a, b) || multimap.EqualUnordered(a, b) {
+			t.Errorf("%s: expected multimaps of different values to differ", name)
+		}
+	}
+}
+
+func TestEqualOrder(t *testing.T) {
+	a := slicemultimap.New[string, int]()
+	a.PutAll("a", []int{1, 2, 2})
+	b := slicemultimap.New[string, int]()
+	b.PutAll("a", []int{2, 1, 2})
+
+	if multimap.Equal[string, int](a, b) {
+		t.Errorf("expected %v and %v to differ in order", a.Entries(), b.Entries())
+	}
+	if !multimap.EqualUnordered[string, int](a, b) {
+		t.Errorf("expected %v and %v to be equal regardless of order", a.Entries(), b.Entries())
+	}
+
+	c := slicemultimap.New[string, int]()
+	c.PutAll("a", []int{1, 1, 2})
+	if multimap.EqualUnordered[string, int](a, c) {
+		t.Errorf("expected %v and %v to differ in multiplicity", a.Entries(), c.Entries())
+	}
+}
+
+func TestEqualAcrossImplementations(t *testing.T) {
+	impls := implementations()
+	for name, impl := range impls {
+		a := impl.new()
+		a.PutAll("a", []int{1, 2, 3})
+		a.Put("b", 4)
+		for otherName, other := range impls {
+			b := other.new()
+			b.Put("b", 4)
+			b.PutAll("a", []int{3, 2, 1})
+			if !multimap.EqualUnordered(a, b) {
+				t.Errorf("%s, %s: expected %v, got %v", name, otherName, a.Entries(), b.Entries())
+			}
+			if actualValue, expectedValue := multimap.Hash(b), multimap.Hash(a); actualValue != expectedValue {
+				t.Errorf("%s, %s: expected %v, got %v", name, otherName, expectedValue, actualValue)
+			}
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	for name, impl := range implementations() {
+		a, b := impl.new(), impl.new()
+		if actualValue, expectedValue := multimap.Hash(a), multimap.Hash(b); actualValue != expectedValue {
+			t.Errorf("%s: expected %v, got %v", name, expectedValue, actualValue)
+		}
+
+		a.PutAll("a", []int{1, 2, 3})
+		a.PutAll("b", []int{4, 5})
+		b.PutAll("b", []int{5, 4})
+		b.PutAll("a", []int{3, 1, 2})
+		if actualValue, expectedValue := multimap.Hash(b), multimap.Hash(a); actualValue != expectedValue {
+			t.Errorf("%s: expected %v, got %v", name, expectedValue, actualValue)
+		}
+
+		b.Remove("b", 5)
+		b.Put("a", 5)
+		if multimap.Hash(a) == multimap.Hash(b) {
+			t.Errorf("%s: expected different hashes for %v and %v", name, a.Entries(), b.Entries())
+		}
+
+		if impl.duplicates {
+			c, d := impl.new(), impl.new()
+			c.PutAll("a", []int{1, 1})
+			d.PutAll("a", []int{2, 2})
+			if multimap.Hash(c) == multimap.Hash(d) {
+				t.Errorf("%s: expected different hashes for %v and %v", name, c.Entries(), d.Entries())
+			}
+			d.Clear()
+			d.Put("a", 1)
+			if multimap.Hash(c) == multimap.Hash(d) {
+				t.Errorf("%s: expected different hashes for %v and %v", name, c.Entries(), d.Entries())
+			}
+		}
+	}
+}
@@ -6,13 +6,15 @@ import (
 )
 
 func main() {
-	usPresidents := []struct {
+	type president struct {
 		firstName  string
 		middleName string
 		lastName   string
 		termStart  int
 		termEnd    int
-	}{
+	}
+
+	usPresidents := []president{
 		{"George", "", "Washington", 1789, 1797},
 		{"John", "", "Adams", 1797, 1801},
 		{"Thomas", "", "Jefferson", 1801, 1809},
@@ -29,11 +31,9 @@ func main() {
 		{"Barack", "Hussein", "Obama", 2009, 2017},
 	}
 
-	m := slicemultimap.New[string, string]()
-
-	for _, president := range usPresidents {
-		m.Put(president.firstName, president.lastName)
-	}
+	m := slicemultimap.GroupBy(usPresidents,
+		func(p president) string { return p.firstName },
+		func(p president) string { return p.lastName })
 
 	for _, firstName := range m.KeySet() {
 		lastNames, _ := m.Get(firstName)
@@ -0,0 +1,446 @@
+// Package codec implements the binary encoding shared by the multimap implementations.
+//
+// A multimap is encoded with a length-prefixed per-key layout:
+//
+//	version      byte
+//	keys         uvarint
+//	keys times:
+//	  key        element
+//	  values     uvarint
+//	  values times:
+//	    value    element
+//
+// Multimaps that keep the global insertion order of their key-value pairs
+// use an entry-list layout instead, which preserves how pairs of different keys interleave:
+//
+//	version      byte
+//	entries      uvarint
+//	entries times:
+//	  key        element
+//	  value      element
+//
+// Elements are encoded according to their kind: booleans as a single byte,
+// signed integers as varints, unsigned integers as uvarints, floating-point
+// and complex numbers as little-endian IEEE 754 bits, and strings as a uvarint
+// length followed by their bytes. Zero-size types are encoded as a single zero byte.
+// Types implementing encoding.BinaryMarshaler and encoding.BinaryUnmarshaler use
+// their own encoding, and any other type is encoded with encoding/gob, both
+// prefixed by a uvarint length.
+//
+// Every element takes at least one byte, which allows the decoder to reject
+// counts that cannot be satisfied by the remaining input before allocating.
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"reflect"
+)
+
+// Version is the version of the multimap encodings written by AppendMultiMap and AppendEntries.
+const Version = 1
+
+// ErrCorrupt is returned when the input is not a valid encoding.
+var ErrCorrupt = errors.New("multimap: corrupt binary encoding")
+
+// Set holds a key with its values decoded from a multimap encoding.
+type Set[K comparable, V any] struct {
+	Key    K
+	Values []V
+}
+
+// AppendMultiMap appends the encoding of a multimap with the given number of distinct keys to b.
+// The sets iterator must yield exactly keys distinct keys together with their values.
+func AppendMultiMap[K comparable, V any](b []byte, keys int, sets iter.Seq2[K, []V]) ([]byte, error) {
+	keyCodec, valueCodec := For[K](), For[V]()
+	b = append(b, Version)
+	b = binary.AppendUvarint(b, uint64(keys))
+
+	var err error
+	written := 0
+	for key, values := range sets {
+		if b, err = keyCodec.Append(b, key); err != nil {
+			return nil, err
+		}
+		b = binary.AppendUvarint(b, uint64(len(values)))
+		for _, value := range values {
+			if b, err = valueCodec.Append(b, value); err != nil {
+				return nil, err
+			}
+		}
+		written++
+	}
+	if written != keys {
+		return nil, fmt.Errorf("multimap: expected %d keys, got %d", keys, written)
+	}
+	return b, nil
+}
+
+// DecodeMultiMap decodes a multimap encoded by AppendMultiMap.
+// The whole input has to be consumed by the encoding.
+func DecodeMultiMap[K comparable, V any](data []byte) ([]Set[K, V], error) {
+	keyCodec, valueCodec := For[K](), For[V]()
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: missing version", ErrCorrupt)
+	}
+	if data[0] != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrCorrupt, data[0])
+	}
+	data = data[1:]
+
+	keys, data, err := ReadCount(data)
+	if err != nil {
+		return nil, err
+	}
+	sets := make([]Set[K, V], keys)
+	for i := range sets {
+		if sets[i].Key, data, err = keyCodec.Read(data); err != nil {
+			return nil, err
+		}
+		var values int
+		if values, data, err = ReadCount(data); err != nil {
+			return nil, err
+		}
+		sets[i].Values = make([]V, values)
+		for j := range sets[i].Values {
+			if sets[i].Values[j], data, err = valueCodec.Read(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrCorrupt, len(data))
+	}
+	return sets, nil
+}
+
+// Entry holds a key-value pair decoded from an entry-list encoding.
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// AppendEntries appends the entry-list encoding of a multimap with the given number of key-value pairs to b.
+// The all iterator must yield exactly entries key-value pairs, in the order they are to be decoded.
+func AppendEntries[K comparable, V any](b []byte, entries int, all iter.Seq2[K, V]) ([]byte, error) {
+	keyCodec, valueCodec := For[K](), For[V]()
+	b = append(b, Version)
+	b = binary.AppendUvarint(b, uint64(entries))
+
+	var err error
+	written := 0
+	for key, value := range all {
+		if b, err = keyCodec.Append(b, key); err != nil {
+			return nil, err
+		}
+		if b, err = valueCodec.Append(b, value); err != nil {
+			return nil, err
+		}
+		written++
+	}
+	if written != entries {
+		return nil, fmt.Errorf("multimap: expected %d entries, got %d", entries, written)
+	}
+	return b, nil
+}
+
+// DecodeEntries decodes a multimap encoded by AppendEntries.
+// The whole input has to be consumed by the encoding.
+func DecodeEntries[K comparable, V any](data []byte) ([]Entry[K, V], error) {
+	keyCodec, valueCodec := For[K](), For[V]()
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: missing version", ErrCorrupt)
+	}
+	if data[0] != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrCorrupt, data[0])
+	}
+	data = data[1:]
+
+	count, data, err := ReadCount(data)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry[K, V], count)
+	for i := range entries {
+		if entries[i].Key, data, err = keyCodec.Read(data); err != nil {
+			return nil, err
+		}
+		if entries[i].Value, data, err = valueCodec.Read(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrCorrupt, len(data))
+	}
+	return entries, nil
+}
+
+// ReadCount reads a uvarint count of elements from data and returns it with the remaining data.
+// Since every element takes at least one byte, counts exceeding the remaining data are rejected.
+func ReadCount(data []byte) (int, []byte, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("%w: invalid count", ErrCorrupt)
+	}
+	data = data[n:]
+	if count > uint64(len(data)) {
+		return 0, nil, fmt.Errorf("%w: count %d exceeds remaining %d bytes", ErrCorrupt, count, len(data))
+	}
+	return int(count), data, nil
+}
+
+// Codec appends and reads elements of type T.
+type Codec[T any] struct {
+	Append func(b []byte, v T) ([]byte, error)
+	Read   func(data []byte) (v T, rest []byte, err error)
+}
+
+// For returns the codec of elements of type T.
+func For[T any]() Codec[T] {
+	if c, found := builtin[reflect.TypeFor[T]()]; found {
+		return c.(Codec[T])
+	}
+
+	t := reflect.TypeFor[T]()
+	switch {
+	case t.Implements(reflect.TypeFor[encoding.BinaryMarshaler]()) &&
+		reflect.PointerTo(t).Implements(reflect.TypeFor[encoding.BinaryUnmarshaler]()):
+		return Codec[T]{Append: appendBinaryMarshaler[T], Read: readBinaryUnmarshaler[T]}
+	case t.Size() == 0 && t.Kind() != reflect.Interface:
+		return Codec[T]{Append: appendZero[T], Read: readZero[T]}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Codec[T]{
+			Append: func(b []byte, v T) ([]byte, error) {
+				if reflect.ValueOf(&v).Elem().Bool() {
+					return append(b, 1), nil
+				}
+				return append(b, 0), nil
+			},
+			Read: func(data []byte) (v T, rest []byte, err error) {
+				if len(data) == 0 || data[0] > 1 {
+					return v, nil, fmt.Errorf("%w: invalid bool", ErrCorrupt)
+				}
+				reflect.ValueOf(&v).Elem().SetBool(data[0] == 1)
+				return v, data[1:], nil
+			},
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Codec[T]{
+			Append: func(b []byte, v T) ([]byte, error) {
+				return binary.AppendVarint(b, reflect.ValueOf(&v).Elem().Int()), nil
+			},
+			Read: func(data []byte) (v T, rest []byte, err error) {
+				i, n := binary.Varint(data)
+				e := reflect.ValueOf(&v).Elem()
+				if n <= 0 || e.OverflowInt(i) {
+					return v, nil, fmt.Errorf("%w: invalid %v", ErrCorrupt, t)
+				}
+				e.SetInt(i)
+				return v, data[n:], nil
+			},
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Codec[T]{
+			Append: func(b []byte, v T) ([]byte, error) {
+				return binary.AppendUvarint(b, reflect.ValueOf(&v).Elem().Uint()), nil
+			},
+			Read: func(data []byte) (v T, rest []byte, err error) {
+				u, n := binary.Uvarint(data)
+				e := reflect.ValueOf(&v).Elem()
+				if n <= 0 || e.OverflowUint(u) {
+					return v, nil, fmt.Errorf("%w: invalid %v", ErrCorrupt, t)
+				}
+				e.SetUint(u)
+				return v, data[n:], nil
+			},
+		}
+	case reflect.Float32, reflect.Float64:
+		size := int(t.Size())
+		return Codec[T]{
+			Append: func(b []byte, v T) ([]byte, error) {
+				return appendFloat(b, reflect.ValueOf(&v).Elem().Float(), size), nil
+			},
+			Read: func(data []byte) (v T, rest []byte, err error) {
+				f, data, err := readFloat(data, size)
+				if err != nil {
+					return v, nil, err
+				}
+				reflect.ValueOf(&v).Elem().SetFloat(f)
+				return v, data, nil
+			},
+		}
+	case reflect.Complex64, reflect.Complex128:
+		size := int(t.Size()) / 2
+		return Codec[T]{
+			Append: func(b []byte, v T) ([]byte, error) {
+				c := reflect.ValueOf(&v).Elem().Complex()
+				return appendFloat(appendFloat(b, real(c), size), imag(c), size), nil
+			},
+			Read: func(data []byte) (v T, rest []byte, err error) {
+				re, data, err := readFloat(data, size)
+				if err != nil {
+					return v, nil, err
+				}
+				im, data, err := readFloat(data, size)
+				if err != nil {
+					return v, nil, err
+				}
+				reflect.ValueOf(&v).Elem().SetComplex(complex(re, im))
+				return v, data, nil
+			},
+		}
+	case reflect.String:
+		return Codec[T]{
+			Append: func(b []byte, v T) ([]byte, error) {
+				s := reflect.ValueOf(&v).Elem().String()
+				return append(binary.AppendUvarint(b, uint64(len(s))), s...), nil
+			},
+			Read: func(data []byte) (v T, rest []byte, err error) {
+				s, data, err := readBytes(data)
+				if err != nil {
+					return v, nil, err
+				}
+				reflect.ValueOf(&v).Elem().SetString(string(s))
+				return v, data, nil
+			},
+		}
+	}
+	return Codec[T]{Append: appendGob[T], Read: readGob[T]}
+}
+
+// builtin holds codecs of predeclared types, which avoid the use of reflection for every element.
+var builtin = map[reflect.Type]any{
+	reflect.TypeFor[string](): Codec[string]{
+		Append: func(b []byte, v string) ([]byte, error) {
+			return append(binary.AppendUvarint(b, uint64(len(v))), v...), nil
+		},
+		Read: func(data []byte) (string, []byte, error) {
+			s, data, err := readBytes(data)
+			return string(s), data, err
+		},
+	},
+	reflect.TypeFor[int]():    varintCodec[int](),
+	reflect.TypeFor[int8]():   varintCodec[int8](),
+	reflect.TypeFor[int16]():  varintCodec[int16](),
+	reflect.TypeFor[int32]():  varintCodec[int32](),
+	reflect.TypeFor[int64]():  varintCodec[int64](),
+	reflect.TypeFor[uint]():   uvarintCodec[uint](),
+	reflect.TypeFor[uint8]():  uvarintCodec[uint8](),
+	reflect.TypeFor[uint16](): uvarintCodec[uint16](),
+	reflect.TypeFor[uint32](): uvarintCodec[uint32](),
+	reflect.TypeFor[uint64](): uvarintCodec[uint64](),
+}
+
+func varintCodec[T int | int8 | int16 | int32 | int64]() Codec[T] {
+	return Codec[T]{
+		Append: func(b []byte, v T) ([]byte, error) {
+			return binary.AppendVarint(b, int64(v)), nil
+		},
+		Read: func(data []byte) (T, []byte, error) {
+			i, n := binary.Varint(data)
+			if n <= 0 || int64(T(i)) != i {
+				return 0, nil, fmt.Errorf("%w: invalid %v", ErrCorrupt, reflect.TypeFor[T]())
+			}
+			return T(i), data[n:], nil
+		},
+	}
+}
+
+func uvarintCodec[T uint | uint8 | uint16 | uint32 | uint64]() Codec[T] {
+	return Codec[T]{
+		Append: func(b []byte, v T) ([]byte, error) {
+			return binary.AppendUvarint(b, uint64(v)), nil
+		},
+		Read: func(data []byte) (T, []byte, error) {
+			u, n := binary.Uvarint(data)
+			if n <= 0 || uint64(T(u)) != u {
+				return 0, nil, fmt.Errorf("%w: invalid %v", ErrCorrupt, reflect.TypeFor[T]())
+			}
+			return T(u), data[n:], nil
+		},
+	}
+}
+
+func appendZero[T any](b []byte, _ T) ([]byte, error) {
+	return append(b, 0), nil
+}
+
+func readZero[T any](data []byte) (v T, rest []byte, err error) {
+	if len(data) == 0 || data[0] != 0 {
+		return v, nil, fmt.Errorf("%w: invalid %v", ErrCorrupt, reflect.TypeFor[T]())
+	}
+	return v, data[1:], nil
+}
+
+func appendFloat(b []byte, f float64, size int) []byte {
+	if size == 4 {
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(f)))
+	}
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(f))
+}
+
+func readFloat(data []byte, size int) (float64, []byte, error) {
+	if len(data) < size {
+		return 0, nil, fmt.Errorf("%w: truncated float", ErrCorrupt)
+	}
+	if size == 4 {
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), data[4:], nil
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(data)), data[8:], nil
+}
+
+// readBytes reads a uvarint length followed by that many bytes.
+func readBytes(data []byte) ([]byte, []byte, error) {
+	length, data, err := ReadCount(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data[:length], data[length:], nil
+}
+
+func appendBinaryMarshaler[T any](b []byte, v T) ([]byte, error) {
+	data, err := any(v).(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(binary.AppendUvarint(b, uint64(len(data))), data...), nil
+}
+
+func readBinaryUnmarshaler[T any](data []byte) (v T, rest []byte, err error) {
+	element, data, err := readBytes(data)
+	if err != nil {
+		return v, nil, err
+	}
+	if err := any(&v).(encoding.BinaryUnmarshaler).UnmarshalBinary(element); err != nil {
+		return v, nil, err
+	}
+	return v, data, nil
+}
+
+func appendGob[T any](b []byte, v T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return append(binary.AppendUvarint(b, uint64(buf.Len())), buf.Bytes()...), nil
+}
+
+func readGob[T any](data []byte) (v T, rest []byte, err error) {
+	element, data, err := readBytes(data)
+	if err != nil {
+		return v, nil, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(element)).Decode(&v); err != nil {
+		return v, nil, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	return v, data, nil
+}
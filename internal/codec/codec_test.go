@@ -0,0 +1,201 @@
+package codec
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type id int
+
+type label string
+
+type point struct {
+	X, Y int
+}
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+
+	roundTrip(t, []string{"", "a", "héllo"})
+	roundTrip(t, []int{0, -1, 1, -1 << 63, 1<<63 - 1})
+	roundTrip(t, []int8{-128, 127})
+	roundTrip(t, []uint16{0, 65535})
+	roundTrip(t, []uint64{0, 1<<64 - 1})
+	roundTrip(t, []bool{true, false})
+	roundTrip(t, []float32{0, -1.5, 3.25})
+	roundTrip(t, []float64{0, -1.5, 1e300})
+	roundTrip(t, []complex128{1 + 2i, -3i})
+	roundTrip(t, []id{-7, 42})
+	roundTrip(t, []label{"x", ""})
+	roundTrip(t, []struct{}{{}, {}})
+	roundTrip(t, []point{{1, 2}, {-3, 4}})
+	roundTrip(t, []time.Time{now, now.Add(time.Hour)})
+	roundTrip(t, [][2]int{{1, 2}, {3, 4}})
+}
+
+func roundTrip[T comparable](t *testing.T, values []T) {
+	t.Helper()
+	c := For[T]()
+	var b []byte
+	var err error
+	for _, value := range values {
+		if b, err = c.Append(b, value); err != nil {
+			t.Fatalf("%T: unexpected error: %v", value, err)
+		}
+	}
+	for _, expectedValue := range values {
+		var actualValue T
+		if actualValue, b, err = c.Read(b); err != nil {
+			t.Fatalf("%T: unexpected error: %v", expectedValue, err)
+		}
+		if actualValue != expectedValue {
+			t.Errorf("%T: expected %v, got %v", expectedValue, expectedValue, actualValue)
+		}
+	}
+	if len(b) != 0 {
+		t.Errorf("%T: expected %v trailing bytes, got %v", values, 0, len(b))
+	}
+}
+
+func TestMultiMap(t *testing.T) {
+	sets := []Set[string, int]{
+		{Key: "a", Values: []int{3, 1, 3}},
+		{Key: "b", Values: []int{-2}},
+	}
+	data, err := AppendMultiMap(nil, len(sets), func(yield func(string, []int) bool) {
+		for _, set := range sets {
+			if !yield(set.Key, set.Values) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual, err := DecodeMultiMap[string, int](data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(actual) != len(sets) {
+		t.Fatalf("expected %v, got %v", sets, actual)
+	}
+	for i := range sets {
+		if actual[i].Key != sets[i].Key || !slices.Equal(actual[i].Values, sets[i].Values) {
+			t.Errorf("expected %v, got %v", sets[i], actual[i])
+		}
+	}
+
+	if _, err := AppendMultiMap(nil, 3, func(yield func(string, []int) bool) {}); err == nil {
+		t.Errorf("expected an error for a wrong number of keys")
+	}
+}
+
+func TestEntries(t *testing.T) {
+	entries := []Entry[string, int]{{Key: "a", Value: 3}, {Key: "b", Value: -2}, {Key: "a", Value: 1}}
+	data, err := AppendEntries(nil, len(entries), func(yield func(string, int) bool) {
+		for _, entry := range entries {
+			if !yield(entry.Key, entry.Value) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual, err := DecodeEntries[string, int](data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(actual, entries) {
+		t.Errorf("expected %v, got %v", entries, actual)
+	}
+
+	if _, err := AppendEntries(nil, 2, func(yield func(string, int) bool) {}); err == nil {
+		t.Errorf("expected an error for a wrong number of entries")
+	}
+}
+
+func TestDecodeEntriesCorrupt(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"version", []byte{2, 0}},
+		{"missing entries", []byte{Version}},
+		{"too many entries", []byte{Version, 5, 1, 'a', 0}},
+		{"truncated key", []byte{Version, 1, 3, 'a'}},
+		{"missing value", []byte{Version, 1, 1, 'a'}},
+		{"truncated value", []byte{Version, 1, 1, 'a', 0x80}},
+		{"trailing bytes", []byte{Version, 1, 1, 'a', 2, 0}},
+	}
+
+	for _, test := range tests {
+		if _, err := DecodeEntries[string, int](test.data); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: expected %v, got %v", test.name, ErrCorrupt, err)
+		}
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"version", []byte{2, 0}},
+		{"missing keys", []byte{Version}},
+		{"too many keys", []byte{Version, 5, 1, 'a', 0}},
+		{"truncated key", []byte{Version, 1, 3, 'a'}},
+		{"missing values", []byte{Version, 1, 1, 'a'}},
+		{"too many values", []byte{Version, 1, 1, 'a', 9, 2}},
+		{"truncated value", []byte{Version, 1, 1, 'a', 1, 0x80}},
+		{"overflowing count", []byte{Version, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{"trailing bytes", []byte{Version, 1, 1, 'a', 1, 2, 0}},
+	}
+
+	for _, test := range tests {
+		if _, err := DecodeMultiMap[string, int](test.data); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: expected %v, got %v", test.name, ErrCorrupt, err)
+		}
+	}
+
+	if _, _, err := For[int8]().Read([]byte{0x80, 0x02}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected %v, got %v", ErrCorrupt, err)
+	}
+	if _, _, err := For[bool]().Read([]byte{2}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected %v, got %v", ErrCorrupt, err)
+	}
+	if _, _, err := For[point]().Read([]byte{2, 0xff, 0xff}); err == nil {
+		t.Errorf("expected an error for a corrupt gob element")
+	}
+}
+
+func FuzzDecodeMultiMap(f *testing.F) {
+	f.Add([]byte{Version, 1, 1, 'a', 2, 2, 4})
+	f.Add([]byte{Version, 0})
+	f.Add([]byte{Version, 2, 0, 1, 0, 1, 'b', 1, 1})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sets, err := DecodeMultiMap[string, int](data)
+		if err != nil {
+			return
+		}
+		encoded, err := AppendMultiMap(nil, len(sets), func(yield func(string, []int) bool) {
+			for _, set := range sets {
+				if !yield(set.Key, set.Values) {
+					return
+				}
+			}
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := DecodeMultiMap[string, int](encoded); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package multimap
+
+// Invert returns a new multimap, created by newMultiMap, holding a value-key pair for every key-value pair of src.
+// Key-value pairs contained several times in src are inverted as many times,
+// unless the returned multimap rejects duplicate key-value pairs.
+func Invert[K comparable, V comparable, M MultiMap[V, K]](src MultiMap[K, V], newMultiMap func() M) M {
+	dst := newMultiMap()
+	InvertInto(src, dst)
+	return dst
+}
+
+// InvertInto puts a value-key pair for every key-value pair of src into dst,
+// keeping the key-value pairs dst already contains.
+// Key-value pairs are put in the iteration order of src.
+func InvertInto[K comparable, V comparable](src MultiMap[K, V], dst MultiMap[V, K]) {
+	for key, value := range src.All() {
+		dst.Put(value, key)
+	}
+}
@@ -0,0 +1,102 @@
+package multimap_test
+
+import (
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/linkedmultimap"
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+	"github.com/rafos/go-multimap/treemultimap"
+)
+
+func TestInvert(t *testing.T) {
+	userGroups := slicemultimap.New[string, string]()
+	userGroups.PutAll("alice", []string{"admins", "staff"})
+	userGroups.PutAll("bob", []string{"staff", "staff"})
+	userGroups.Put("carol", "guests")
+
+	groupUsers := multimap.Invert(userGroups, slicemultimap.New[string, string])
+
+	expected := slicemultimap.New[string, string]()
+	expected.Put("admins", "alice")
+	expected.PutAll("staff", []string{"alice", "bob", "bob"})
+	expected.Put("guests", "carol")
+	if !multimap.EqualUnordered[string, string](groupUsers, expected) {
+		t.Errorf("expected %v, got %v", expected.Entries(), groupUsers.Entries())
+	}
+	if actualValue, expectedValue := groupUsers.Size(), userGroups.Size(); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	inverted := multimap.Invert(groupUsers, slicemultimap.New[string, string])
+	if !multimap.EqualUnordered[string, string](inverted, userGroups) {
+		t.Errorf("expected %v, got %v", userGroups.Entries(), inverted.Entries())
+	}
+}
+
+func TestInvertSet(t *testing.T) {
+	m := slicemultimap.New[int, string]()
+	m.PutAll(1, []string{"a", "a", "b"})
+	m.Put(2, "a")
+
+	inverted := multimap.Invert(m, setmultimap.New[string, int])
+
+	expected := setmultimap.New[string, int]()
+	expected.PutAll("a", []int{1, 2})
+	expected.Put("b", 1)
+	if !multimap.EqualUnordered[string, int](inverted, expected) {
+		t.Errorf("expected %v, got %v", expected.Entries(), inverted.Entries())
+	}
+}
+
+func TestInvertOrder(t *testing.T) {
+	m := linkedmultimap.New[string, int]()
+	m.Put("b", 1)
+	m.Put("a", 1)
+	m.Put("c", 2)
+	m.Put("a", 2)
+
+	inverted := multimap.Invert(m, linkedmultimap.New[int, string])
+	if actualValue, _ := inverted.Get(1); !sameOrder(actualValue, []string{"b", "a"}) {
+		t.Errorf("expected %v, got %v", []string{"b", "a"}, actualValue)
+	}
+	if actualValue, _ := inverted.Get(2); !sameOrder(actualValue, []string{"c", "a"}) {
+		t.Errorf("expected %v, got %v", []string{"c", "a"}, actualValue)
+	}
+}
+
+func TestInvertInto(t *testing.T) {
+	userGroups := treemultimap.NewOrdered[string, string]()
+	userGroups.PutAll("alice", []string{"admins", "staff"})
+	userGroups.Put("bob", "staff")
+
+	groupUsers := setmultimap.New[string, string]()
+	groupUsers.Put("staff", "dave")
+	multimap.InvertInto[string, string](userGroups, groupUsers)
+
+	expected := setmultimap.New[string, string]()
+	expected.Put("admins", "alice")
+	expected.PutAll("staff", []string{"alice", "bob", "dave"})
+	if !multimap.EqualUnordered[string, string](groupUsers, expected) {
+		t.Errorf("expected %v, got %v", expected.Entries(), groupUsers.Entries())
+	}
+
+	empty := slicemultimap.New[string, string]()
+	multimap.InvertInto[string, string](empty, groupUsers)
+	if actualValue := groupUsers.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+}
+
+func sameOrder[E comparable](a []E, b []E) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
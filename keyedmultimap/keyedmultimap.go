@@ -0,0 +1,264 @@
+// Package keyedmultimap implements a multimap whose keys are matched after normalization.
+//
+// A keyedmultimap is a multimap that can hold duplicate key-value pairs
+// and that maintains the insertion ordering of values for a given key, like a slicemultimap.
+// Keys are normalized by the function given to the constructor before they are looked up,
+// so keys with the same normalized form share their values:
+// with strings.ToLower as normalization, Get("Content-Type") and Get("content-type") return the same values.
+//
+// The multimap remembers the spelling a key was first put with and reports it
+// in KeySet, Keys, Entries and the iterators. Once all values of a key are removed,
+// the key is forgotten and the next Put records its spelling again.
+//
+// Elements are unordered in the map.
+//
+// Structure is not thread safe.
+package keyedmultimap
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/rafos/go-multimap"
+)
+
+var _ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+
+// bucket holds the values of a normalized key together with the key's first-seen spelling.
+type bucket[K comparable, V comparable] struct {
+	key    K
+	values []V
+}
+
+// MultiMap holds the elements in go's native map, indexed by normalized key.
+type MultiMap[K comparable, V comparable] struct {
+	m         map[K]*bucket[K, V]
+	size      int
+	normalize func(K) K
+}
+
+// New instantiates a new multimap matching keys by their normalized form.
+// The normalize function has to be deterministic, for example strings.ToLower or strings.TrimSpace.
+func New[K comparable, V comparable](normalize func(K) K) *MultiMap[K, V] {
+	return &MultiMap[K, V]{m: make(map[K]*bucket[K, V]), normalize: normalize}
+}
+
+// Key returns the spelling the key was first put with.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Key(key K) (original K, found bool) {
+	b, found := m.m[m.normalize(key)]
+	if !found {
+		return original, false
+	}
+	return b.key, true
+}
+
+// Get searches the element in the multimap by key.
+// It returns a copy of its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	b, found := m.m[m.normalize(key)]
+	if !found {
+		return nil, false
+	}
+	return slices.Clone(b.values), true
+}
+
+// Put stores a key-value pair in this multimap.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	normalized := m.normalize(key)
+	b, found := m.m[normalized]
+	if !found {
+		b = &bucket[K, V]{key: key}
+		m.m[normalized] = b
+	}
+	b.values = append(b.values, value)
+	m.size++
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	for _, value := range values {
+		m.Put(key, value)
+	}
+}
+
+// Contains returns true if this multimap contains at least one key-value pair with the key key and the value value.
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	b, found := m.m[m.normalize(key)]
+	return found && slices.Contains(b.values, value)
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) (found bool) {
+	_, found = m.m[m.normalize(key)]
+	return
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	for _, b := range m.m {
+		if slices.Contains(b.values, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes a single key-value pair from this multimap, if such exists.
+// When the key holds the value several times, its first occurrence is removed.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	normalized := m.normalize(key)
+	b, found := m.m[normalized]
+	if !found {
+		return
+	}
+	i := slices.Index(b.values, value)
+	if i < 0 {
+		return
+	}
+	m.size--
+	if len(b.values) == 1 {
+		delete(m.m, normalized)
+		return
+	}
+	b.values = append(b.values[:i:i], b.values[i+1:]...)
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	normalized := m.normalize(key)
+	if b, found := m.m[normalized]; found {
+		m.size -= len(b.values)
+		delete(m.m, normalized)
+	}
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	return m.size == 0
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	return m.size
+}
+
+// Keys returns a view collection containing the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates. Keys are returned in their first-seen spelling.
+func (m *MultiMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.size)
+	for _, b := range m.m {
+		for range b.values {
+			keys = append(keys, b.key)
+		}
+	}
+	return keys
+}
+
+// KeySet returns all distinct keys contained in this multimap, in their first-seen spelling.
+func (m *MultiMap[K, V]) KeySet() []K {
+	keys := make([]K, 0, len(m.m))
+	for _, b := range m.m {
+		keys = append(keys, b.key)
+	}
+	return keys
+}
+
+// Values returns all values from each key-value pair contained in this multimap.
+// This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
+func (m *MultiMap[K, V]) Values() []V {
+	values := make([]V, 0, m.size)
+	for _, b := range m.m {
+		values = append(values, b.values...)
+	}
+	return values
+}
+
+// Entries view collection of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances.
+// Retrieving the key and value from the entries result will be as trivial as:
+//   - var entry = m.Entries()[0]
+//   - var key = entry.Key
+//   - var value = entry.Value
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	entries := make([]multimap.Entry[K, V], 0, m.size)
+	for _, b := range m.m {
+		for _, value := range b.values {
+			entries = append(entries, multimap.Entry[K, V]{Key: b.key, Value: value})
+		}
+	}
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, b := range m.m {
+			for _, value := range b.values {
+				if !yield(b.key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, b := range m.m {
+			for range b.values {
+				if !yield(b.key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, b := range m.m {
+			for _, value := range b.values {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with their values.
+// The yielded values are not copied and must not be modified.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for _, b := range m.m {
+			if !yield(b.key, b.values[:len(b.values):len(b.values)]) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.m = make(map[K]*bucket[K, V])
+	m.size = 0
+}
+
+// Clone returns a copy of the multimap using the same normalization function.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	c := &MultiMap[K, V]{m: make(map[K]*bucket[K, V], len(m.m)), size: m.size, normalize: m.normalize}
+	for normalized, b := range m.m {
+		c.m[normalized] = &bucket[K, V]{key: b.key, values: slices.Clone(b.values)}
+	}
+	return c
+}
@@ -0,0 +1,181 @@
+package keyedmultimap
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
+)
+
+func TestCaseInsensitiveKeys(t *testing.T) {
+	m := New[string, string](strings.ToLower)
+	m.Put("Content-Type", "text/html")
+	m.Put("content-type", "charset=utf-8")
+	m.Put("Accept", "*/*")
+
+	for _, key := range []string{"Content-Type", "content-type", "CONTENT-TYPE"} {
+		if actualValue, found := m.Get(key); !found || !slices.Equal(actualValue, []string{"text/html", "charset=utf-8"}) {
+			t.Errorf("Get(%q): expected %v, got %v, %v", key, []string{"text/html", "charset=utf-8"}, actualValue, found)
+		}
+		if !m.ContainsKey(key) || !m.Contains(key, "text/html") {
+			t.Errorf("Contains(%q): expected %v, got %v", key, true, false)
+		}
+	}
+	if m.Contains("accept", "text/html") {
+		t.Errorf("expected %v, got %v", false, true)
+	}
+	if actualValue := m.Size(); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []string{"Content-Type", "Accept"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []string{"Content-Type", "Content-Type", "Accept"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	expectedEntries := []multimap.Entry[string, string]{
+		{Key: "Content-Type", Value: "text/html"},
+		{Key: "Content-Type", Value: "charset=utf-8"},
+		{Key: "Accept", Value: "*/*"},
+	}
+	if actualValue := m.Entries(); !sameElements(actualValue, expectedEntries) {
+		t.Errorf("expected %v, got %v", expectedEntries, actualValue)
+	}
+	if actualValue, found := m.Key("ACCEPT"); !found || actualValue != "Accept" {
+		t.Errorf("expected %v, got %v, %v", "Accept", actualValue, found)
+	}
+	if actualValue, found := m.Key("Host"); found || actualValue != "" {
+		t.Errorf("expected %v, got %v, %v", "", actualValue, found)
+	}
+}
+
+func TestRemoveForgetsSpelling(t *testing.T) {
+	m := New[string, int](strings.ToLower)
+	m.PutAll("Alice@Example.com", []int{1, 2})
+
+	m.Remove("alice@example.com", 1)
+	if actualValue, _ := m.Key("ALICE@EXAMPLE.COM"); actualValue != "Alice@Example.com" {
+		t.Errorf("expected %v, got %v", "Alice@Example.com", actualValue)
+	}
+	m.Remove("ALICE@example.com", 2)
+	if m.ContainsKey("alice@example.com") || !m.Empty() {
+		t.Errorf("expected an empty multimap, got %v", m.Entries())
+	}
+
+	m.Put("alice@example.com", 3)
+	if actualValue := m.KeySet(); !slices.Equal(actualValue, []string{"alice@example.com"}) {
+		t.Errorf("expected %v, got %v", []string{"alice@example.com"}, actualValue)
+	}
+
+	m.Put("Bob@example.com", 4)
+	m.RemoveAll("BOB@EXAMPLE.COM")
+	m.RemoveAll("carol@example.com")
+	if actualValue := m.Size(); actualValue != 1 {
+		t.Errorf("expected %v, got %v", 1, actualValue)
+	}
+	if m.ContainsValue(4) || !m.ContainsValue(3) {
+		t.Errorf("expected ContainsValue to reflect removals")
+	}
+
+	m.Clear()
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+}
+
+func TestNormalizedHostnames(t *testing.T) {
+	hostname := func(host string) string {
+		return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	}
+	m := New[string, int](hostname)
+	m.Put("Example.com.", 80)
+	m.Put(" example.COM", 443)
+
+	if actualValue, _ := m.Get("example.com"); !slices.Equal(actualValue, []int{80, 443}) {
+		t.Errorf("expected %v, got %v", []int{80, 443}, actualValue)
+	}
+	if actualValue := m.KeySet(); !slices.Equal(actualValue, []string{"Example.com."}) {
+		t.Errorf("expected %v, got %v", []string{"Example.com."}, actualValue)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := New[string, int](strings.ToLower)
+	m.PutAll("A", []int{3, 1, 2})
+	m.Put("b", 4)
+
+	c := m.Clone()
+	if !multimap.Equal[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+
+	c.Put("a", 5)
+	c.Remove("B", 4)
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.Contains("b", 4) || m.Contains("a", 5) {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+	if actualValue, _ := c.Get("a"); !slices.Equal(actualValue, []int{3, 1, 2, 5}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 5}, actualValue)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return New[string, string](func(key string) string { return key })
+	}, multimaptest.List)
+}
+
+func TestIterators(t *testing.T) {
+	m := New[string, int](strings.ToLower)
+	m.PutAll("A", []int{1, 2})
+	m.Put("a", 3)
+	m.Put("B", 4)
+
+	var entries []multimap.Entry[string, int]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[string, int]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := slices.Collect(m.KeysSeq()), []string{"A", "A", "A", "B"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := slices.Collect(m.ValuesSeq()), m.Values(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keySet []string
+	for key, values := range m.Sets() {
+		keySet = append(keySet, key)
+		if expectedValue, _ := m.Get(key); !slices.Equal(values, expectedValue) {
+			t.Errorf("key %s: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+	if actualValue, expectedValue := keySet, []string{"A", "B"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[V]int)
+	for _, value := range a {
+		counts[value]++
+	}
+	for _, value := range b {
+		if counts[value] == 0 {
+			return false
+		}
+		counts[value]--
+	}
+	return true
+}
@@ -0,0 +1,385 @@
+// Package linkedsetmultimap implements a multimap backed by go's native map and linked lists.
+//
+// A linkedsetmultimap is a multimap that cannot hold duplicate key-value pairs.
+// Adding a key-value pair that's already in the multimap has no effect.
+//
+// This multimap is typically known as LinkedHashMultimap in other languages.
+//
+// Elements are ordered by first insertion: Get returns the values of a key
+// in the order they were first put, while Entries, Keys and Values return
+// the key-value pairs in the order they were first put across all keys.
+// KeySet returns the keys in the order they were first put.
+// Removing a key-value pair and putting it again moves it to the end.
+//
+// Structure is not thread safe.
+package linkedsetmultimap
+
+import (
+	"encoding"
+	"encoding/gob"
+	"iter"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+var (
+	_ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+	_ encoding.BinaryMarshaler    = &MultiMap[any, any]{}
+	_ encoding.BinaryUnmarshaler  = &MultiMap[any, any]{}
+	_ gob.GobEncoder              = &MultiMap[any, any]{}
+	_ gob.GobDecoder              = &MultiMap[any, any]{}
+)
+
+// node holds a single key-value pair.
+// It is linked both in the list of all pairs and in the list of pairs of its key.
+type node[K comparable, V comparable] struct {
+	key   K
+	value V
+
+	prev, next           *node[K, V]
+	prevInKey, nextInKey *node[K, V]
+}
+
+// bucket holds all pairs of a single key.
+// It is linked in the list of all keys.
+type bucket[K comparable, V comparable] struct {
+	nodes      map[V]*node[K, V]
+	head, tail *node[K, V]
+
+	prev, next *bucket[K, V]
+}
+
+// MultiMap holds the elements in go's native map and keeps them in linked lists.
+type MultiMap[K comparable, V comparable] struct {
+	m map[K]*bucket[K, V]
+
+	head, tail        *node[K, V]
+	firstKey, lastKey *bucket[K, V]
+	size              int
+}
+
+// New instantiates a new multimap.
+func New[K comparable, V comparable]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{m: make(map[K]*bucket[K, V])}
+}
+
+// Get searches the element in the multimap by key.
+// It returns its values in first insertion order or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	b, found := m.m[key]
+	if !found {
+		return nil, false
+	}
+	values = make([]V, 0, len(b.nodes))
+	for n := b.head; n != nil; n = n.nextInKey {
+		values = append(values, n.value)
+	}
+	return values, true
+}
+
+// Put stores a key-value pair in this multimap.
+// It has no effect if the key-value pair is already present.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	b, found := m.m[key]
+	if !found {
+		b = &bucket[K, V]{nodes: make(map[V]*node[K, V]), prev: m.lastKey}
+		if m.lastKey == nil {
+			m.firstKey = b
+		} else {
+			m.lastKey.next = b
+		}
+		m.lastKey = b
+		m.m[key] = b
+	} else if _, found = b.nodes[value]; found {
+		return
+	}
+
+	n := &node[K, V]{key: key, value: value, prev: m.tail, prevInKey: b.tail}
+	if m.tail == nil {
+		m.head = n
+	} else {
+		m.tail.next = n
+	}
+	m.tail = n
+	if b.tail == nil {
+		b.head = n
+	} else {
+		b.tail.nextInKey = n
+	}
+	b.tail = n
+	b.nodes[value] = n
+	m.size++
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	for _, value := range values {
+		m.Put(key, value)
+	}
+}
+
+// Contains returns true if this multimap contains a key-value pair with the key key and the value value.
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	b, found := m.m[key]
+	if !found {
+		return false
+	}
+	_, found = b.nodes[value]
+	return found
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) (found bool) {
+	_, found = m.m[key]
+	return
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	for _, b := range m.m {
+		if _, found := b.nodes[value]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the key-value pair from this multimap, if such exists.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	b, found := m.m[key]
+	if !found {
+		return
+	}
+	n, found := b.nodes[value]
+	if !found {
+		return
+	}
+
+	if n.prev == nil {
+		m.head = n.next
+	} else {
+		n.prev.next = n.next
+	}
+	if n.next == nil {
+		m.tail = n.prev
+	} else {
+		n.next.prev = n.prev
+	}
+	if n.prevInKey == nil {
+		b.head = n.nextInKey
+	} else {
+		n.prevInKey.nextInKey = n.nextInKey
+	}
+	if n.nextInKey == nil {
+		b.tail = n.prevInKey
+	} else {
+		n.nextInKey.prevInKey = n.prevInKey
+	}
+	delete(b.nodes, value)
+	m.size--
+
+	if len(b.nodes) == 0 {
+		m.removeBucket(key, b)
+	}
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	b, found := m.m[key]
+	if !found {
+		return
+	}
+	for n := b.head; n != nil; n = n.nextInKey {
+		if n.prev == nil {
+			m.head = n.next
+		} else {
+			n.prev.next = n.next
+		}
+		if n.next == nil {
+			m.tail = n.prev
+		} else {
+			n.next.prev = n.prev
+		}
+	}
+	m.size -= len(b.nodes)
+	m.removeBucket(key, b)
+}
+
+// removeBucket unlinks the bucket of the key from the list of all keys.
+func (m *MultiMap[K, V]) removeBucket(key K, b *bucket[K, V]) {
+	if b.prev == nil {
+		m.firstKey = b.next
+	} else {
+		b.prev.next = b.next
+	}
+	if b.next == nil {
+		m.lastKey = b.prev
+	} else {
+		b.next.prev = b.prev
+	}
+	delete(m.m, key)
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	return m.size == 0
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	return m.size
+}
+
+// Keys returns a view collection containing the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates. Keys are returned in the order their pairs were first put.
+func (m *MultiMap[K, V]) Keys() []K {
+	keys := make([]K, m.size)
+	count := 0
+	for n := m.head; n != nil; n = n.next {
+		keys[count] = n.key
+		count++
+	}
+	return keys
+}
+
+// KeySet returns all distinct keys contained in this multimap in the order they were first put.
+func (m *MultiMap[K, V]) KeySet() []K {
+	keys := make([]K, 0, len(m.m))
+	for b := m.firstKey; b != nil; b = b.next {
+		keys = append(keys, b.head.key)
+	}
+	return keys
+}
+
+// Values returns all values from each key-value pair contained in this multimap.
+// This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
+// Values are returned in the order their pairs were first put.
+func (m *MultiMap[K, V]) Values() []V {
+	values := make([]V, m.size)
+	count := 0
+	for n := m.head; n != nil; n = n.next {
+		values[count] = n.value
+		count++
+	}
+	return values
+}
+
+// Entries view collection of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances in the order they were first put.
+// Retrieving the key and value from the entries result will be as trivial as:
+//   - var entry = m.Entries()[0]
+//   - var key = entry.Key
+//   - var value = entry.Value
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	entries := make([]multimap.Entry[K, V], m.size)
+	count := 0
+	for n := m.head; n != nil; n = n.next {
+		entries[count] = multimap.Entry[K, V]{Key: n.key, Value: n.value}
+		count++
+	}
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap in the order they were first put.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := m.head; n != nil; n = n.next {
+			if !yield(n.key, n.value) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap in the order they were first put.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for n := m.head; n != nil; n = n.next {
+			if !yield(n.key) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap in the order they were first put.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for n := m.head; n != nil; n = n.next {
+			if !yield(n.value) {
+				return
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with their values in the order the keys were first put.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for b := m.firstKey; b != nil; b = b.next {
+			values := make([]V, 0, len(b.nodes))
+			for n := b.head; n != nil; n = n.nextInKey {
+				values = append(values, n.value)
+			}
+			if !yield(b.head.key, values) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.m = make(map[K]*bucket[K, V])
+	m.head, m.tail = nil, nil
+	m.firstKey, m.lastKey = nil, nil
+	m.size = 0
+}
+
+// Clone returns a deep copy of the multimap.
+// The clone keeps the ordering of all keys, values and entries, and can be modified independently of the multimap.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	c := &MultiMap[K, V]{m: make(map[K]*bucket[K, V], len(m.m))}
+	for n := m.head; n != nil; n = n.next {
+		c.Put(n.key, n.value)
+	}
+	return c
+}
+
+// MarshalBinary encodes the multimap into a compact binary form.
+// Every key-value pair is written in the order of Entries, so the decoded multimap keeps the same ordering.
+func (m *MultiMap[K, V]) MarshalBinary() ([]byte, error) {
+	return codec.AppendEntries(nil, m.size, m.All())
+}
+
+// UnmarshalBinary decodes a multimap encoded by MarshalBinary, replacing all elements of the multimap.
+// Corrupt input yields an error and leaves the multimap unchanged.
+func (m *MultiMap[K, V]) UnmarshalBinary(data []byte) error {
+	entries, err := codec.DecodeEntries[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.Clear()
+	for _, entry := range entries {
+		m.Put(entry.Key, entry.Value)
+	}
+	return nil
+}
+
+// GobEncode encodes the multimap for encoding/gob using MarshalBinary.
+func (m *MultiMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode decodes the multimap for encoding/gob using UnmarshalBinary.
+func (m *MultiMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
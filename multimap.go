@@ -1,13 +1,15 @@
 package multimap
 
+import "iter"
+
 // Entry represents a key/value pair inside a multimap.
-type Entry[K comparable, V comparable] struct {
+type Entry[K comparable, V any] struct {
 	Key   K
 	Value V
 }
 
 // MultiMap interface that all multimaps implement.
-type MultiMap[K comparable, V comparable] interface {
+type MultiMap[K comparable, V any] interface {
 	Get(key K) (value []V, found bool)
 
 	Put(key K, value V)
@@ -25,6 +27,11 @@ type MultiMap[K comparable, V comparable] interface {
 	KeySet() []K
 	Values() []V
 
+	All() iter.Seq2[K, V]
+	KeysSeq() iter.Seq[K]
+	ValuesSeq() iter.Seq[V]
+	Sets() iter.Seq2[K, []V]
+
 	Clear()
 	Empty() bool
 	Size() int
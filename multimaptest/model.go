@@ -0,0 +1,182 @@
+package multimaptest
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rafos/go-multimap"
+)
+
+// model is the reference implementation the multimap under test is compared to.
+// It keeps the values of every key in insertion order, rejecting duplicates under Set semantics.
+type model struct {
+	semantics Semantics
+	values    map[string][]string
+	size      int
+	// seen holds every key and value ever put, to check that removed ones are no longer contained.
+	seen map[multimap.Entry[string, string]]bool
+}
+
+func newModel(semantics Semantics) *model {
+	return &model{
+		semantics: semantics,
+		values:    make(map[string][]string),
+		seen:      map[multimap.Entry[string, string]]bool{{Key: "absent", Value: "absent"}: true},
+	}
+}
+
+func (m *model) put(key, value string) {
+	m.seen[multimap.Entry[string, string]{Key: key, Value: value}] = true
+	if m.semantics == Set && slices.Contains(m.values[key], value) {
+		return
+	}
+	m.values[key] = append(m.values[key], value)
+	m.size++
+}
+
+func (m *model) remove(key, value string) {
+	values := m.values[key]
+	i := slices.Index(values, value)
+	if i < 0 {
+		return
+	}
+	m.size--
+	if len(values) == 1 {
+		delete(m.values, key)
+		return
+	}
+	m.values[key] = slices.Delete(slices.Clone(values), i, i+1)
+}
+
+func (m *model) removeAll(key string) {
+	m.size -= len(m.values[key])
+	delete(m.values, key)
+}
+
+func (m *model) clear() {
+	m.values = make(map[string][]string)
+	m.size = 0
+}
+
+// sameValues reports whether the values of a key match, in order under List semantics.
+func (m *model) sameValues(expected, actual []string) bool {
+	if m.semantics == List {
+		return slices.Equal(expected, actual)
+	}
+	return sameElements(expected, actual)
+}
+
+// diff returns a description of every view of the multimap mm that does not match the model.
+func (m *model) diff(mm multimap.MultiMap[string, string]) []error {
+	var errs []error
+	errorf := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	var entries []multimap.Entry[string, string]
+	var keySet, keys, values []string
+	for key, vs := range m.values {
+		keySet = append(keySet, key)
+		for _, value := range vs {
+			entries = append(entries, multimap.Entry[string, string]{Key: key, Value: value})
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+	}
+
+	if actual := mm.Size(); actual != m.size {
+		errorf("Size: expected %v, got %v", m.size, actual)
+	}
+	if actual := mm.Empty(); actual != (m.size == 0) {
+		errorf("Empty: expected %v, got %v", m.size == 0, actual)
+	}
+	if actual := mm.Entries(); !sameElements(actual, entries) {
+		errorf("Entries: expected %v, got %v", entries, actual)
+	}
+	if actual := mm.Keys(); !sameElements(actual, keys) {
+		errorf("Keys: expected %v, got %v", keys, actual)
+	}
+	if actual := mm.KeySet(); !sameElements(actual, keySet) {
+		errorf("KeySet: expected %v, got %v", keySet, actual)
+	}
+	if actual := mm.Values(); !sameElements(actual, values) {
+		errorf("Values: expected %v, got %v", values, actual)
+	}
+
+	for key, expected := range m.values {
+		actual, found := mm.Get(key)
+		if !found || !m.sameValues(expected, actual) {
+			errorf("Get(%q): expected %v, got %v, %v", key, expected, actual, found)
+		}
+		if !mm.ContainsKey(key) {
+			errorf("ContainsKey(%q): expected %v, got %v", key, true, false)
+		}
+		for _, value := range expected {
+			if !mm.Contains(key, value) {
+				errorf("Contains(%q, %q): expected %v, got %v", key, value, true, false)
+			}
+			if !mm.ContainsValue(value) {
+				errorf("ContainsValue(%q): expected %v, got %v", value, true, false)
+			}
+		}
+	}
+	for entry := range m.seen {
+		if _, found := m.values[entry.Key]; !found {
+			if actual, found := mm.Get(entry.Key); found || len(actual) != 0 {
+				errorf("Get(%q): expected %v, got %v, %v", entry.Key, nil, actual, found)
+			}
+			if mm.ContainsKey(entry.Key) {
+				errorf("ContainsKey(%q): expected %v, got %v", entry.Key, false, true)
+			}
+		}
+		if !slices.Contains(m.values[entry.Key], entry.Value) && mm.Contains(entry.Key, entry.Value) {
+			errorf("Contains(%q, %q): expected %v, got %v", entry.Key, entry.Value, false, true)
+		}
+		if !slices.Contains(values, entry.Value) && mm.ContainsValue(entry.Value) {
+			errorf("ContainsValue(%q): expected %v, got %v", entry.Value, false, true)
+		}
+	}
+
+	var all []multimap.Entry[string, string]
+	for key, value := range mm.All() {
+		all = append(all, multimap.Entry[string, string]{Key: key, Value: value})
+	}
+	if !sameElements(all, entries) {
+		errorf("All: expected %v, got %v", entries, all)
+	}
+	if actual := slices.Collect(mm.KeysSeq()); !sameElements(actual, keys) {
+		errorf("KeysSeq: expected %v, got %v", keys, actual)
+	}
+	if actual := slices.Collect(mm.ValuesSeq()); !sameElements(actual, values) {
+		errorf("ValuesSeq: expected %v, got %v", values, actual)
+	}
+	var sets []string
+	for key, actual := range mm.Sets() {
+		sets = append(sets, key)
+		if expected := m.values[key]; !m.sameValues(expected, actual) {
+			errorf("Sets: key %q: expected %v, got %v", key, expected, actual)
+		}
+	}
+	if !sameElements(sets, keySet) {
+		errorf("Sets: expected keys %v, got %v", keySet, sets)
+	}
+	return errs
+}
+
+// sameElements reports whether a and b hold the same elements the same number of times.
+func sameElements[E comparable](a, b []E) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[E]int, len(a))
+	for _, e := range a {
+		counts[e]++
+	}
+	for _, e := range b {
+		if counts[e] == 0 {
+			return false
+		}
+		counts[e]--
+	}
+	return true
+}
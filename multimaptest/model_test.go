@@ -0,0 +1,50 @@
+package multimaptest
+
+import (
+	"testing"
+
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+)
+
+func TestDiff(t *testing.T) {
+	m := slicemultimap.New[string, string]()
+	model := newModel(List)
+	for _, value := range []string{"b", "a", "b"} {
+		m.Put("1", value)
+		model.put("1", value)
+	}
+	if errs := model.diff(m); len(errs) != 0 {
+		t.Errorf("expected no differences, got %v", errs)
+	}
+
+	m.RemoveAll("1")
+	m.PutAll("1", []string{"a", "b", "b"})
+	if errs := model.diff(m); len(errs) == 0 {
+		t.Errorf("expected a difference in the ordering of values")
+	}
+
+	m.Put("2", "c")
+	if errs := model.diff(m); len(errs) < 5 {
+		t.Errorf("expected differences in every view, got %v", errs)
+	}
+}
+
+func TestDiffSet(t *testing.T) {
+	model := newModel(Set)
+	model.put("1", "a")
+	model.put("1", "b")
+	model.put("1", "a")
+
+	m := setmultimap.New[string, string]()
+	m.PutAll("1", []string{"b", "a", "a"})
+	if errs := model.diff(m); len(errs) != 0 {
+		t.Errorf("expected no differences, got %v", errs)
+	}
+
+	duplicates := slicemultimap.New[string, string]()
+	duplicates.PutAll("1", []string{"a", "b", "a"})
+	if errs := model.diff(duplicates); len(errs) == 0 {
+		t.Errorf("expected a difference for duplicate key-value pairs")
+	}
+}
@@ -0,0 +1,282 @@
+// Package multimaptest implements a conformance test suite for implementations of multimap.MultiMap.
+//
+// An implementation is tested by calling RunConformance from one of its tests:
+//
+//	func TestConformance(t *testing.T) {
+//		multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+//			return slicemultimap.New[string, string]()
+//		}, multimaptest.List)
+//	}
+//
+// Every test starts from a new multimap created by the factory. After each operation,
+// all views of the multimap (Get, Contains, ContainsKey, ContainsValue, Entries, Keys, KeySet,
+// Values, Size, Empty and the iterators) are checked against a reference model.
+package multimaptest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+)
+
+// Semantics describes which key-value pairs a multimap holds and how it orders the values of a key.
+type Semantics int
+
+const (
+	// List semantics hold duplicate key-value pairs and keep the insertion ordering of values for a given key.
+	// Remove removes the first occurrence of a key-value pair.
+	// This is the behaviour of slicemultimap, linkedmultimap and treemultimap.
+	List Semantics = iota
+	// Set semantics reject duplicate key-value pairs, the values of a key may be in any order.
+	// This is the behaviour of setmultimap and linkedsetmultimap.
+	Set
+)
+
+func (s Semantics) String() string {
+	switch s {
+	case List:
+		return "List"
+	case Set:
+		return "Set"
+	}
+	return fmt.Sprintf("Semantics(%d)", int(s))
+}
+
+// RunConformance runs the conformance test suite as subtests of t.
+// Every subtest creates the multimaps it needs by calling newMultiMap, which has to return an empty multimap.
+func RunConformance(t *testing.T, newMultiMap func() multimap.MultiMap[string, string], semantics Semantics) {
+	t.Helper()
+	tests := []struct {
+		name string
+		run  func(t *testing.T, h *harness)
+	}{
+		{"Empty", testEmpty},
+		{"Put", testPut},
+		{"PutDuplicates", testPutDuplicates},
+		{"PutAll", testPutAll},
+		{"Get", testGet},
+		{"Contains", testContains},
+		{"Remove", testRemove},
+		{"RemoveAll", testRemoveAll},
+		{"Clear", testClear},
+		{"Iterators", testIterators},
+		{"ZeroValues", testZeroValues},
+		{"Many", testMany},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newHarness(t, newMultiMap(), semantics))
+		})
+	}
+}
+
+func testEmpty(t *testing.T, h *harness) {
+	h.check()
+	if values, found := h.m.Get("a"); found || len(values) != 0 {
+		t.Errorf("Get on an empty multimap: expected %v, got %v, %v", nil, values, found)
+	}
+	h.remove("a", "x")
+	h.removeAll("a")
+}
+
+func testPut(t *testing.T, h *harness) {
+	h.put("1", "x")
+	h.put("2", "b")
+	h.put("1", "a")
+	h.put("3", "c")
+	h.put("1", "b")
+}
+
+func testPutDuplicates(t *testing.T, h *harness) {
+	h.put("1", "a")
+	h.put("1", "a")
+	h.put("2", "a")
+	h.put("1", "b")
+	h.put("1", "a")
+}
+
+func testPutAll(t *testing.T, h *harness) {
+	h.putAll("1", []string{"c", "a", "b", "a"})
+	h.putAll("2", []string{"b"})
+	h.putAll("3", nil)
+	h.putAll("1", []string{"d"})
+}
+
+func testGet(t *testing.T, h *harness) {
+	h.putAll("1", []string{"a", "b"})
+
+	// Modifying the returned values must not modify the multimap.
+	values, _ := h.m.Get("1")
+	for i := range values {
+		values[i] = "changed"
+	}
+	h.check()
+	h.put("1", "c")
+}
+
+func testContains(t *testing.T, h *harness) {
+	h.putAll("1", []string{"a", "b"})
+	h.put("2", "b")
+
+	tests := []struct {
+		key, value string
+		expected   bool
+	}{
+		{"1", "a", true},
+		{"1", "b", true},
+		{"2", "b", true},
+		{"2", "a", false},
+		{"3", "a", false},
+	}
+	for _, test := range tests {
+		if actual := h.m.Contains(test.key, test.value); actual != test.expected {
+			t.Errorf("Contains(%q, %q): expected %v, got %v", test.key, test.value, test.expected, actual)
+		}
+	}
+}
+
+func testRemove(t *testing.T, h *harness) {
+	h.putAll("1", []string{"a", "b", "a", "c"})
+	h.putAll("2", []string{"a"})
+
+	h.remove("1", "a")
+	h.remove("1", "x")
+	h.remove("3", "a")
+	h.remove("2", "a")
+	h.remove("1", "a")
+	h.remove("1", "c")
+	h.remove("1", "b")
+	h.put("1", "d")
+}
+
+func testRemoveAll(t *testing.T, h *harness) {
+	h.putAll("1", []string{"a", "b", "a"})
+	h.putAll("2", []string{"a"})
+
+	h.removeAll("1")
+	h.removeAll("3")
+	h.putAll("1", []string{"c"})
+	h.removeAll("2")
+	h.removeAll("1")
+}
+
+func testClear(t *testing.T, h *harness) {
+	h.putAll("1", []string{"a", "b", "a"})
+	h.putAll("2", []string{"a"})
+
+	h.clear()
+	h.clear()
+	h.put("1", "a")
+}
+
+func testIterators(t *testing.T, h *harness) {
+	h.putAll("1", []string{"a", "b", "a"})
+	h.putAll("2", []string{"c"})
+	h.putAll("3", []string{"d", "e"})
+
+	count := 0
+	for range h.m.All() {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	for range h.m.KeysSeq() {
+		count++
+		break
+	}
+	for range h.m.ValuesSeq() {
+		count++
+		break
+	}
+	for range h.m.Sets() {
+		count++
+		break
+	}
+	if count != 5 {
+		t.Errorf("expected iterators to stop early, got %v yielded elements", count)
+	}
+}
+
+func testZeroValues(t *testing.T, h *harness) {
+	h.put("", "")
+	h.put("", "a")
+	h.put("a", "")
+	h.remove("", "")
+	h.removeAll("")
+}
+
+func testMany(t *testing.T, h *harness) {
+	for i := 0; i < 200; i++ {
+		h.model.put(fmt.Sprint(i%17), fmt.Sprint(i%5))
+		h.m.Put(fmt.Sprint(i%17), fmt.Sprint(i%5))
+	}
+	h.check()
+	for i := 0; i < 200; i += 3 {
+		h.model.remove(fmt.Sprint(i%17), fmt.Sprint(i%5))
+		h.m.Remove(fmt.Sprint(i%17), fmt.Sprint(i%5))
+	}
+	h.check()
+	for i := 0; i < 17; i += 2 {
+		h.removeAll(fmt.Sprint(i))
+	}
+}
+
+// harness applies operations to a multimap and to its reference model,
+// and checks after each operation that both hold the same key-value pairs.
+type harness struct {
+	t     testing.TB
+	m     multimap.MultiMap[string, string]
+	model *model
+}
+
+func newHarness(t testing.TB, m multimap.MultiMap[string, string], semantics Semantics) *harness {
+	return &harness{t: t, m: m, model: newModel(semantics)}
+}
+
+func (h *harness) put(key, value string) {
+	h.t.Helper()
+	h.m.Put(key, value)
+	h.model.put(key, value)
+	h.check()
+}
+
+func (h *harness) putAll(key string, values []string) {
+	h.t.Helper()
+	h.m.PutAll(key, slices.Clone(values))
+	for _, value := range values {
+		h.model.put(key, value)
+	}
+	h.check()
+}
+
+func (h *harness) remove(key, value string) {
+	h.t.Helper()
+	h.m.Remove(key, value)
+	h.model.remove(key, value)
+	h.check()
+}
+
+func (h *harness) removeAll(key string) {
+	h.t.Helper()
+	h.m.RemoveAll(key)
+	h.model.removeAll(key)
+	h.check()
+}
+
+func (h *harness) clear() {
+	h.t.Helper()
+	h.m.Clear()
+	h.model.clear()
+	h.check()
+}
+
+// check reports every difference between the views of the multimap and the model.
+func (h *harness) check() {
+	h.t.Helper()
+	for _, err := range h.model.diff(h.m) {
+		h.t.Error(err)
+	}
+}
@@ -0,0 +1,194 @@
+package multimaptest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+)
+
+// Keys and values of the operations are drawn from small domains,
+// so that random sequences often hit the same key-value pairs.
+var (
+	keyDomain   = []string{"a", "b", "c", ""}
+	valueDomain = []string{"x", "y", "z", ""}
+)
+
+type opKind int
+
+const (
+	opPut opKind = iota
+	opPutAll
+	opRemove
+	opRemoveAll
+	opClear
+	opKinds
+)
+
+// op is a single operation applied to the multimap under test and to the model.
+type op struct {
+	kind   opKind
+	key    string
+	values []string
+}
+
+func (o op) String() string {
+	switch o.kind {
+	case opPut:
+		return fmt.Sprintf("m.Put(%q, %q)", o.key, o.values[0])
+	case opPutAll:
+		return fmt.Sprintf("m.PutAll(%q, %#v)", o.key, o.values)
+	case opRemove:
+		return fmt.Sprintf("m.Remove(%q, %q)", o.key, o.values[0])
+	case opRemoveAll:
+		return fmt.Sprintf("m.RemoveAll(%q)", o.key)
+	default:
+		return "m.Clear()"
+	}
+}
+
+// decodeOps decodes a sequence of operations from arbitrary bytes.
+// Every operation takes three bytes: the kind in the low and the number of additional PutAll values
+// in the high bits of the first byte, the key and the value. PutAll takes up to three more bytes for its values.
+// Trailing bytes not forming a whole operation are ignored.
+func decodeOps(data []byte) []op {
+	var ops []op
+	for len(data) >= 3 {
+		o := op{
+			kind:   opKind(data[0]&0x0f) % opKinds,
+			key:    keyDomain[int(data[1])%len(keyDomain)],
+			values: []string{valueDomain[int(data[2])%len(valueDomain)]},
+		}
+		n := int(data[0]>>4) % 4
+		data = data[3:]
+		if o.kind == opPutAll {
+			for ; n > 0 && len(data) > 0; n-- {
+				o.values = append(o.values, valueDomain[int(data[0])%len(valueDomain)])
+				data = data[1:]
+			}
+		}
+		ops = append(ops, o)
+	}
+	return ops
+}
+
+// runOps applies ops to a new multimap and to the model, comparing both after every operation.
+// It returns an error describing the first difference or panic.
+func runOps(newMultiMap func() multimap.MultiMap[string, string], semantics Semantics, ops []op) (err error) {
+	i := -1
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("operation %d panicked: %v", i, r)
+		}
+	}()
+
+	m := newMultiMap()
+	model := newModel(semantics)
+	if errs := model.diff(m); len(errs) > 0 {
+		return fmt.Errorf("new multimap: %w", errors.Join(errs...))
+	}
+	for i = range ops {
+		o := ops[i]
+		switch o.kind {
+		case opPut:
+			m.Put(o.key, o.values[0])
+			model.put(o.key, o.values[0])
+		case opPutAll:
+			m.PutAll(o.key, append([]string(nil), o.values...))
+			for _, value := range o.values {
+				model.put(o.key, value)
+			}
+		case opRemove:
+			m.Remove(o.key, o.values[0])
+			model.remove(o.key, o.values[0])
+		case opRemoveAll:
+			m.RemoveAll(o.key)
+			model.removeAll(o.key)
+		case opClear:
+			m.Clear()
+			model.clear()
+		}
+		if errs := model.diff(m); len(errs) > 0 {
+			return fmt.Errorf("after operation %d: %w", i, errors.Join(errs...))
+		}
+	}
+	return nil
+}
+
+// shrink returns a shorter sequence of operations that still fails, by repeatedly removing
+// chunks of operations, from halves of the sequence down to single operations.
+func shrink(newMultiMap func() multimap.MultiMap[string, string], semantics Semantics, ops []op) ([]op, error) {
+	err := runOps(newMultiMap, semantics, ops)
+	for size := len(ops) / 2; size >= 1; {
+		removed := false
+		for start := 0; start+size <= len(ops); {
+			candidate := append(append([]op(nil), ops[:start]...), ops[start+size:]...)
+			if candidateErr := runOps(newMultiMap, semantics, candidate); candidateErr != nil {
+				ops, err, removed = candidate, candidateErr, true
+			} else {
+				start += size
+			}
+		}
+		if !removed {
+			size /= 2
+		}
+	}
+	return ops, err
+}
+
+// report fails t with the shrunk sequence of operations reproducing a failure.
+func report(t testing.TB, newMultiMap func() multimap.MultiMap[string, string], semantics Semantics, ops []op) {
+	t.Helper()
+	ops, err := shrink(newMultiMap, semantics, ops)
+	lines := make([]string, len(ops))
+	for i, o := range ops {
+		lines[i] = "\t" + o.String()
+	}
+	t.Fatalf("%v semantics violated by %d operations:\n%s\n%v", semantics, len(ops), strings.Join(lines, "\n"), err)
+}
+
+// RunModel drives random sequences of operations against multimaps created by newMultiMap
+// and against a reference model, comparing every view of both after each operation.
+// The sequences are generated from a fixed seed, so failures are reproducible.
+// A failing sequence is shrunk before it is reported.
+func RunModel(t *testing.T, newMultiMap func() multimap.MultiMap[string, string], semantics Semantics) {
+	t.Helper()
+	sequences := 200
+	if testing.Short() {
+		sequences = 20
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < sequences; i++ {
+		data := make([]byte, 3*(1+r.Intn(40)))
+		r.Read(data)
+		ops := decodeOps(data)
+		if err := runOps(newMultiMap, semantics, ops); err != nil {
+			report(t, newMultiMap, semantics, ops)
+		}
+	}
+}
+
+// FuzzModel is the native fuzzing counterpart of RunModel.
+// The fuzzer mutates the bytes the sequences of operations are decoded from.
+//
+//	func FuzzModel(f *testing.F) {
+//		multimaptest.FuzzModel(f, func() multimap.MultiMap[string, string] {
+//			return slicemultimap.New[string, string]()
+//		}, multimaptest.List)
+//	}
+func FuzzModel(f *testing.F, newMultiMap func() multimap.MultiMap[string, string], semantics Semantics) {
+	f.Helper()
+	f.Add([]byte{})
+	f.Add([]byte{byte(opPut), 0, 0, byte(opPut), 0, 0, byte(opRemove), 0, 0})
+	f.Add([]byte{byte(opPutAll) | 3<<4, 1, 0, 1, 0, 2, byte(opRemove), 1, 0, byte(opRemove), 1, 2})
+	f.Add([]byte{byte(opPutAll) | 2<<4, 2, 3, 3, 0, byte(opRemoveAll), 2, 0, byte(opClear), 0, 0, byte(opPut), 3, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := decodeOps(data)
+		if err := runOps(newMultiMap, semantics, ops); err != nil {
+			report(t, newMultiMap, semantics, ops)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package multimaptest
+
+import (
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+)
+
+// leaky is a multimap that forgets to remove keys whose last value was removed.
+type leaky struct {
+	*slicemultimap.MultiMap[string, string]
+	empty map[string]bool
+}
+
+func (m *leaky) Remove(key, value string) {
+	m.MultiMap.Remove(key, value)
+	if !m.ContainsKey(key) {
+		m.empty[key] = true
+	}
+}
+
+func (m *leaky) ContainsKey(key string) bool {
+	return m.empty[key] || m.MultiMap.ContainsKey(key)
+}
+
+func newLeaky() multimap.MultiMap[string, string] {
+	return &leaky{MultiMap: slicemultimap.New[string, string](), empty: make(map[string]bool)}
+}
+
+func TestDecodeOps(t *testing.T) {
+	ops := decodeOps([]byte{byte(opPut), 1, 2, byte(opPutAll) | 2<<4, 0, 0, 1, 2, byte(opClear), 0})
+	if len(ops) != 2 {
+		t.Fatalf("expected %v, got %v", 2, len(ops))
+	}
+	if actualValue, expectedValue := ops[0].String(), `m.Put("b", "z")`; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := ops[1].String(), `m.PutAll("a", []string{"x", "y", "z"})`; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestRunOps(t *testing.T) {
+	newMultiMap := func() multimap.MultiMap[string, string] { return slicemultimap.New[string, string]() }
+	ops := []op{
+		{kind: opPut, key: "a", values: []string{"x"}},
+		{kind: opPutAll, key: "b", values: []string{"x", "y"}},
+		{kind: opRemove, key: "a", values: []string{"x"}},
+		{kind: opPut, key: "c", values: []string{"z"}},
+	}
+	if err := runOps(newMultiMap, List, ops); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := runOps(newLeaky, List, ops); err == nil {
+		t.Errorf("expected an error for a key kept after removing its last value")
+	}
+}
+
+func TestShrink(t *testing.T) {
+	ops := []op{
+		{kind: opPutAll, key: "b", values: []string{"x", "y"}},
+		{kind: opPut, key: "a", values: []string{"x"}},
+		{kind: opPut, key: "c", values: []string{"z"}},
+		{kind: opRemoveAll, key: "c", values: []string{"x"}},
+		{kind: opRemove, key: "a", values: []string{"x"}},
+		{kind: opPut, key: "b", values: []string{"z"}},
+	}
+	shrunk, err := shrink(newLeaky, List, ops)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if len(shrunk) != 2 || shrunk[0].kind != opPut || shrunk[1].kind != opRemove {
+		t.Errorf("expected %v, got %v", ops[1:2], shrunk)
+	}
+}
+
+func TestRunOpsPanic(t *testing.T) {
+	newMultiMap := func() multimap.MultiMap[string, string] { return nil }
+	if err := runOps(newMultiMap, List, nil); err == nil {
+		t.Errorf("expected an error for a panicking multimap")
+	}
+}
@@ -0,0 +1,287 @@
+// Package setmultimap implements a multimap backed by go's native map of sets.
+//
+// A setmultimap is a multimap that cannot hold duplicate key-value pairs.
+// Adding a key-value pair that's already in the multimap has no effect.
+//
+// This multimap is typically known as SetMultimap in other languages.
+//
+// Elements are unordered in the map and values are unordered for a given key.
+//
+// Structure is not thread safe.
+package setmultimap
+
+import (
+	"encoding"
+	"encoding/gob"
+	"iter"
+	"maps"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+var (
+	_ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+	_ encoding.BinaryMarshaler    = &MultiMap[any, any]{}
+	_ encoding.BinaryUnmarshaler  = &MultiMap[any, any]{}
+	_ gob.GobEncoder              = &MultiMap[any, any]{}
+	_ gob.GobDecoder              = &MultiMap[any, any]{}
+)
+
+// MultiMap holds the elements in go's native map of sets.
+type MultiMap[K comparable, V comparable] struct {
+	m map[K]map[V]struct{}
+}
+
+// New instantiates a new multimap.
+func New[K comparable, V comparable]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{m: make(map[K]map[V]struct{})}
+}
+
+// Get searches the element in the multimap by key.
+// It returns its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	set, found := m.m[key]
+	if !found {
+		return nil, false
+	}
+	values = make([]V, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	return values, true
+}
+
+// Put stores a key-value pair in this multimap.
+// It has no effect if the key-value pair is already present.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	set, found := m.m[key]
+	if !found {
+		set = make(map[V]struct{})
+		m.m[key] = set
+	}
+	set[value] = struct{}{}
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	for _, value := range values {
+		m.Put(key, value)
+	}
+}
+
+// Contains returns true if this multimap contains a key-value pair with the key key and the value value.
+func (m *MultiMap[K, V]) Contains(key K, value V) (found bool) {
+	_, found = m.m[key][value]
+	return
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) (found bool) {
+	_, found = m.m[key]
+	return
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	for _, set := range m.m {
+		if _, found := set[value]; found {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the key-value pair from this multimap, if such exists.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	set, found := m.m[key]
+	if !found {
+		return
+	}
+	delete(set, value)
+	if len(set) == 0 {
+		delete(m.m, key)
+	}
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	delete(m.m, key)
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	return len(m.m) == 0
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	size := 0
+	for _, set := range m.m {
+		size += len(set)
+	}
+	return size
+}
+
+// Keys returns a view collection containing the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+func (m *MultiMap[K, V]) Keys() []K {
+	keys := make([]K, m.Size())
+	count := 0
+	for key, set := range m.m {
+		for range set {
+			keys[count] = key
+			count++
+		}
+	}
+	return keys
+}
+
+// KeySet returns all distinct keys contained in this multimap.
+func (m *MultiMap[K, V]) KeySet() []K {
+	keys := make([]K, len(m.m))
+	count := 0
+	for key := range m.m {
+		keys[count] = key
+		count++
+	}
+	return keys
+}
+
+// Values returns all values from each key-value pair contained in this multimap.
+// The same value is returned once for every key it is associated with. (size of Values() = MultiMap.Size()).
+func (m *MultiMap[K, V]) Values() []V {
+	values := make([]V, m.Size())
+	count := 0
+	for _, set := range m.m {
+		for value := range set {
+			values[count] = value
+			count++
+		}
+	}
+	return values
+}
+
+// Entries view collection of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances.
+// Retrieving the key and value from the entries result will be as trivial as:
+//   - var entry = m.Entries()[0]
+//   - var key = entry.Key
+//   - var value = entry.Value
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	entries := make([]multimap.Entry[K, V], m.Size())
+	count := 0
+	for key, set := range m.m {
+		for value := range set {
+			entries[count] = multimap.Entry[K, V]{Key: key, Value: value}
+			count++
+		}
+	}
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, set := range m.m {
+			for value := range set {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key, set := range m.m {
+			for range set {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap.
+// The same value is yielded once for every key it is associated with.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, set := range m.m {
+			for value := range set {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with their values.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for key, set := range m.m {
+			values := make([]V, 0, len(set))
+			for value := range set {
+				values = append(values, value)
+			}
+			if !yield(key, values) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.m = make(map[K]map[V]struct{})
+}
+
+// Clone returns a deep copy of the multimap.
+// The values of every key are copied, so the clone and the multimap can be modified independently.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	c := &MultiMap[K, V]{m: make(map[K]map[V]struct{}, len(m.m))}
+	for key, set := range m.m {
+		c.m[key] = maps.Clone(set)
+	}
+	return c
+}
+
+// MarshalBinary encodes the multimap into a compact binary form.
+// Every key is written once, followed by the number of its values and the values themselves.
+func (m *MultiMap[K, V]) MarshalBinary() ([]byte, error) {
+	return codec.AppendMultiMap(nil, len(m.m), m.Sets())
+}
+
+// UnmarshalBinary decodes a multimap encoded by MarshalBinary, replacing all elements of the multimap.
+// Corrupt input yields an error and leaves the multimap unchanged.
+func (m *MultiMap[K, V]) UnmarshalBinary(data []byte) error {
+	sets, err := codec.DecodeMultiMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.Clear()
+	for _, set := range sets {
+		m.PutAll(set.Key, set.Values)
+	}
+	return nil
+}
+
+// GobEncode encodes the multimap for encoding/gob using MarshalBinary.
+func (m *MultiMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode decodes the multimap for encoding/gob using UnmarshalBinary.
+func (m *MultiMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
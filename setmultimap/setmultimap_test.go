@@ -0,0 +1,812 @@
+package setmultimap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
+)
+
+func TestClear(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	if actualValue := m.Size(); actualValue != 8 {
+		t.Errorf("expected %v, got %v", 8, actualValue)
+	}
+	if actualEmpty := m.Empty(); actualEmpty != false {
+		t.Errorf("expected an empty multimap: %v, got %v", false, actualEmpty)
+	}
+
+	m.Clear()
+
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+	if actualEmpty := m.Empty(); actualEmpty != true {
+		t.Errorf("expected an empty multimap: %v, got %v", true, actualEmpty)
+	}
+}
+func TestPut(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	if actualValue := m.Size(); actualValue != 8 {
+		t.Errorf("expected %v, got %v", 8, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2, 3, 4, 5, 6, 7}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 4, 5, 6, 7}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "c", "d", "e", "f", "g", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "a"},
+		{Key: 1, Value: "x"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 4, Value: "d"},
+		{Key: 5, Value: "e"},
+		{Key: 6, Value: "f"},
+		{Key: 7, Value: "g"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, []string{"a", "x"}, true},
+		{2, []string{"b"}, true},
+		{3, []string{"c"}, true},
+		{4, []string{"d"}, true},
+		{5, []string{"e"}, true},
+		{6, []string{"f"}, true},
+		{7, []string{"g"}, true},
+		{8, nil, false},
+		{9, nil, false},
+	}
+
+	for i, test := range tests {
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+}
+
+func TestPutDuplicates(t *testing.T) {
+	m := New[int, string]()
+	m.Put(1, "a")
+	m.Put(1, "a")
+	m.Put(2, "a")
+	m.PutAll(1, []string{"b", "a", "b"})
+
+	if actualValue := m.Size(); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "a", "b"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := m.Get(1); !sameElements(actualValue, []string{"a", "b"}) {
+		t.Errorf("expected %v, got %v", []string{"a", "b"}, actualValue)
+	}
+
+	m.Remove(1, "a")
+
+	if actualValue, expectedValue := m.Contains(1, "a"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("a"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+}
+
+func TestPutAll(t *testing.T) {
+	m := New[int, string]()
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(2, "b")
+	m.PutAll(1, []string{"a", "x", "y", "x"})
+
+	if actualValue := m.Size(); actualValue != 6 {
+		t.Errorf("expected %v, got %v", 6, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "c", "d", "x", "y"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "a"},
+		{Key: 1, Value: "x"},
+		{Key: 1, Value: "y"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 4, Value: "d"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, []string{"a", "x", "y"}, true},
+		{2, []string{"b"}, true},
+		{3, []string{"c"}, true},
+		{4, []string{"d"}, true},
+		{5, nil, false},
+		{6, nil, false},
+	}
+
+	for i, test := range tests {
+		// Test for retrievals.
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	m := New[int, string]()
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(2, "b")
+	m.PutAll(1, []string{"a", "x", "y"})
+
+	if actualValue, expectedValue := m.Contains(1, "a"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Contains(1, "x"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Contains(1, "z"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsKey(1), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsKey(5), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("x"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("z"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+func TestRemove(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	m.Remove(5, "n")
+	m.Remove(6, "f")
+	m.Remove(7, "g")
+	m.Remove(8, "h")
+	m.Remove(5, "e")
+
+	if actualValue := m.Size(); actualValue != 5 {
+		t.Errorf("expected %v, got %v", 5, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "c", "d", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "a"},
+		{Key: 1, Value: "x"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 4, Value: "d"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, []string{"a", "x"}, true},
+		{2, []string{"b"}, true},
+		{3, []string{"c"}, true},
+		{4, []string{"d"}, true},
+		{5, nil, false},
+		{6, nil, false},
+		{7, nil, false},
+		{8, nil, false},
+		{9, nil, false},
+	}
+
+	for i, test := range tests {
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+
+	m.Remove(1, "a")
+	m.Remove(4, "d")
+	m.Remove(1, "x")
+	m.Remove(3, "c")
+	m.Remove(2, "x")
+	m.Remove(2, "b")
+
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.KeySet()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Values()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Entries()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("expected %v, got %v", true, actualValue)
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	m.RemoveAll(5)
+	m.RemoveAll(6)
+	m.RemoveAll(7)
+	m.RemoveAll(8)
+	m.RemoveAll(5)
+	m.RemoveAll(1)
+	m.RemoveAll(3)
+	m.RemoveAll(2)
+	m.RemoveAll(2)
+	m.RemoveAll(4)
+	m.RemoveAll(9)
+
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.KeySet()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Values()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Entries()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("expected %v, got %v", true, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, nil, false},
+		{2, nil, false},
+		{3, nil, false},
+		{4, nil, false},
+		{5, nil, false},
+		{6, nil, false},
+		{7, nil, false},
+		{8, nil, false},
+		{9, nil, false},
+	}
+
+	for i, test := range tests {
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return New[string, string]()
+	}, multimaptest.Set)
+}
+
+func TestIterators(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(3, "c")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+	m.PutAll(4, []string{"d", "y"})
+
+	var entries []multimap.Entry[int, string]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[int, string]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keys []int
+	for key := range m.KeysSeq() {
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := keys, m.Keys(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var values []string
+	for value := range m.ValuesSeq() {
+		values = append(values, value)
+	}
+	if actualValue, expectedValue := values, m.Values(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keySet []int
+	for key, values := range m.Sets() {
+		keySet = append(keySet, key)
+		if expectedValue, _ := m.Get(key); !sameElements(values, expectedValue) {
+			t.Errorf("key %d: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+	if actualValue, expectedValue := keySet, m.KeySet(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	count := 0
+	for range m.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	for range m.Sets() {
+		count++
+		break
+	}
+	if count != 4 {
+		t.Errorf("expected %v, got %v", 4, count)
+	}
+
+	m.Clear()
+
+	for key, value := range m.All() {
+		t.Errorf("unexpected entry %v: %v", key, value)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("a", []int{3, 1, 2})
+	m.Put("b", 4)
+
+	c := m.Clone()
+	if !multimap.EqualUnordered[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+	if actualValue, expectedValue := multimap.Hash[string, int](c), multimap.Hash[string, int](m); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	c.Put("a", 5)
+	c.Remove("b", 4)
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.Contains("b", 4) || m.Contains("a", 5) {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+
+	m.RemoveAll("a")
+	if actualValue, _ := c.Get("a"); !sameElements(actualValue, []int{3, 1, 2, 5}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 5}, actualValue)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	m := New[int, string]()
+	m.PutAll(2, []string{"b", "a"})
+	m.Put(1, "x")
+	m.Put(3, "")
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := New[int, string]()
+	actual.Put(4, "d")
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual = New[int, string]()
+	if err := gob.NewDecoder(&buf).Decode(actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if err := actual.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated at %d: expected an error", i)
+		}
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av == bv {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check equality of entries.
+func sameEntries[K comparable, V comparable](a []multimap.Entry[K, V], b []multimap.Entry[K, V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av.Key == bv.Key && av.Value == bv.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Utilities for Benchmarking
+func benchmarkGet(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Get(n)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Put(n, struct{}{})
+		}
+	}
+}
+
+func benchmarkPutAll(b *testing.B, m *MultiMap[any, any], size int) {
+	v := make([]interface{}, 0)
+	v = append(v, struct{}{})
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.PutAll(n, v)
+		}
+	}
+}
+
+func benchmarkRemove(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Remove(n, struct{}{})
+		}
+	}
+}
+
+func benchmarkRemoveAll(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.RemoveAll(n)
+		}
+	}
+}
+
+func BenchmarkMultiMapGet100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapGet1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapGet100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapPut100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPut1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPut100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemove100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemove1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemove10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemove100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}
@@ -0,0 +1,359 @@
+// Package shardedmultimap implements a thread safe multimap split into independently locked shards.
+//
+// A shardedmultimap hashes every key to one of its shards. Each shard is a multimap
+// of its own, such as a slicemultimap or a setmultimap, guarded by a read-write mutex.
+// Operations on keys of different shards do not contend for the same lock,
+// which allows a high write throughput from many goroutines.
+//
+// Operations on a single key are atomic. Operations spanning all keys, such as
+// Size, Entries or Clear, visit the shards one after another and do not observe
+// a consistent snapshot of the whole multimap while it is being modified.
+//
+// Slices returned by Get, Entries, Keys, KeySet and Values are copies
+// that the caller may keep and modify without affecting the multimap.
+//
+// Elements are unordered in the map.
+package shardedmultimap
+
+import (
+	"encoding"
+	"encoding/gob"
+	"hash/maphash"
+	"iter"
+	"slices"
+	"sync"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+var (
+	_ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+	_ encoding.BinaryMarshaler    = &MultiMap[any, any]{}
+	_ encoding.BinaryUnmarshaler  = &MultiMap[any, any]{}
+	_ gob.GobEncoder              = &MultiMap[any, any]{}
+	_ gob.GobDecoder              = &MultiMap[any, any]{}
+)
+
+// shard guards a part of the multimap with a read-write mutex.
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  multimap.MultiMap[K, V]
+}
+
+// MultiMap holds the elements in shards selected by the hash of their keys.
+type MultiMap[K comparable, V any] struct {
+	seed     maphash.Seed
+	shards   []shard[K, V]
+	newShard func() multimap.MultiMap[K, V]
+}
+
+// New instantiates a new multimap with the given number of shards, each created by newShard.
+// At least one shard is always created.
+//
+// The key and value types have to be given explicitly, for example:
+//
+//	m := shardedmultimap.New[string, int](16, slicemultimap.New[string, int])
+func New[K comparable, V any, M multimap.MultiMap[K, V]](shards int, newShard func() M) *MultiMap[K, V] {
+	m := &MultiMap[K, V]{
+		seed:     maphash.MakeSeed(),
+		shards:   make([]shard[K, V], max(shards, 1)),
+		newShard: func() multimap.MultiMap[K, V] { return newShard() },
+	}
+	for i := range m.shards {
+		m.shards[i].m = m.newShard()
+	}
+	return m
+}
+
+// shard returns the shard holding the key.
+func (m *MultiMap[K, V]) shard(key K) *shard[K, V] {
+	return &m.shards[maphash.Comparable(m.seed, key)%uint64(len(m.shards))]
+}
+
+// Get searches the element in the multimap by key.
+// It returns a copy of its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values, found = s.m.Get(key)
+	return slices.Clone(values), found
+}
+
+// Put stores a key-value pair in this multimap.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Put(key, value)
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+// All of the values are stored atomically.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.PutAll(key, values)
+}
+
+// Contains returns true if this multimap contains at least one key-value pair with the key key and the value value.
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Contains(key, value)
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) bool {
+	s := m.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.ContainsKey(key)
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		found := s.m.ContainsValue(value)
+		s.mu.RUnlock()
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes a single key-value pair from this multimap, if such exists.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Remove(key, value)
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.RemoveAll(key)
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		empty := s.m.Empty()
+		s.mu.RUnlock()
+		if !empty {
+			return false
+		}
+	}
+	return true
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	size := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		size += s.m.Size()
+		s.mu.RUnlock()
+	}
+	return size
+}
+
+// Keys returns a copy of the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+func (m *MultiMap[K, V]) Keys() []K {
+	var keys []K
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		keys = append(keys, s.m.Keys()...)
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// KeySet returns a copy of all distinct keys contained in this multimap.
+func (m *MultiMap[K, V]) KeySet() []K {
+	var keys []K
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		keys = append(keys, s.m.KeySet()...)
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// Values returns a copy of all values from each key-value pair contained in this multimap.
+// This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
+func (m *MultiMap[K, V]) Values() []V {
+	var values []V
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		values = append(values, s.m.Values()...)
+		s.mu.RUnlock()
+	}
+	return values
+}
+
+// Entries returns a copy of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances.
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	var entries []multimap.Entry[K, V]
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		entries = append(entries, s.m.Entries()...)
+		s.mu.RUnlock()
+	}
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap.
+// A snapshot of each shard is taken when the iteration reaches it, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for i := range m.shards {
+			s := &m.shards[i]
+			s.mu.RLock()
+			entries := s.m.Entries()
+			s.mu.RUnlock()
+			for _, entry := range entries {
+				if !yield(entry.Key, entry.Value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// A snapshot of each shard is taken when the iteration reaches it, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range m.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// A snapshot of each shard is taken when the iteration reaches it, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range m.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with copies of their values.
+// A snapshot of each shard is taken when the iteration reaches it, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		type set struct {
+			key    K
+			values []V
+		}
+		for i := range m.shards {
+			s := &m.shards[i]
+			s.mu.RLock()
+			var sets []set
+			for key, values := range s.m.Sets() {
+				sets = append(sets, set{key: key, values: slices.Clone(values)})
+			}
+			s.mu.RUnlock()
+			for _, set := range sets {
+				if !yield(set.key, set.values) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.Lock()
+		s.m.Clear()
+		s.mu.Unlock()
+	}
+}
+
+// Clone returns a deep copy of the multimap with the same number of shards, each created by newShard.
+// The values of every key are copied in order, so the clone and the multimap can be modified independently.
+// Like Entries, Clone visits the shards one after another.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	c := &MultiMap[K, V]{seed: m.seed, shards: make([]shard[K, V], len(m.shards)), newShard: m.newShard}
+	for i := range m.shards {
+		s := &m.shards[i]
+		c.shards[i].m = c.newShard()
+		s.mu.RLock()
+		for key, values := range s.m.Sets() {
+			c.shards[i].m.PutAll(key, values)
+		}
+		s.mu.RUnlock()
+	}
+	return c
+}
+
+// MarshalBinary encodes the multimap into a compact binary form.
+// Every key is written once, followed by the number of its values and the values themselves.
+func (m *MultiMap[K, V]) MarshalBinary() ([]byte, error) {
+	var sets []codec.Set[K, V]
+	for key, values := range m.Sets() {
+		sets = append(sets, codec.Set[K, V]{Key: key, Values: values})
+	}
+	return codec.AppendMultiMap(nil, len(sets), func(yield func(K, []V) bool) {
+		for _, set := range sets {
+			if !yield(set.Key, set.Values) {
+				return
+			}
+		}
+	})
+}
+
+// UnmarshalBinary decodes a multimap encoded by MarshalBinary, replacing all elements of the multimap.
+// Corrupt input yields an error and leaves the multimap unchanged.
+func (m *MultiMap[K, V]) UnmarshalBinary(data []byte) error {
+	sets, err := codec.DecodeMultiMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.Clear()
+	for _, set := range sets {
+		m.PutAll(set.Key, set.Values)
+	}
+	return nil
+}
+
+// GobEncode encodes the multimap for encoding/gob using MarshalBinary.
+func (m *MultiMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode decodes the multimap for encoding/gob using UnmarshalBinary.
+func (m *MultiMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
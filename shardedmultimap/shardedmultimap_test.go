@@ -0,0 +1,453 @@
+package shardedmultimap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+	"github.com/rafos/go-multimap/syncmultimap"
+)
+
+func TestPut(t *testing.T) {
+	m := New[int, string](4, slicemultimap.New[int, string])
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	if actualValue := m.Size(); actualValue != 8 {
+		t.Errorf("expected %v, got %v", 8, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2, 3, 4, 5, 6, 7}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 4, 5, 6, 7}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "c", "d", "e", "f", "g", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "a"},
+		{Key: 1, Value: "x"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 4, Value: "d"},
+		{Key: 5, Value: "e"},
+		{Key: 6, Value: "f"},
+		{Key: 7, Value: "g"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, []string{"x", "a"}, true},
+		{2, []string{"b"}, true},
+		{7, []string{"g"}, true},
+		{8, nil, false},
+	}
+
+	for i, test := range tests {
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	m := New[int, string](3, setmultimap.New[int, string])
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(2, "b")
+	m.PutAll(1, []string{"a", "x", "y", "x"})
+
+	if actualValue := m.Size(); actualValue != 6 {
+		t.Errorf("expected %v, got %v", 6, actualValue)
+	}
+	if actualValue, expectedValue := m.Contains(1, "a"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Contains(1, "z"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsKey(1), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsKey(5), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("d"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("z"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	m := New[int, string](0, slicemultimap.New[int, string])
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(1, "x")
+	m.Put(1, "a")
+
+	m.Remove(5, "n")
+	m.Remove(6, "f")
+	m.Remove(1, "x")
+	m.RemoveAll(5)
+	m.RemoveAll(9)
+
+	if actualValue := m.Size(); actualValue != 1 {
+		t.Errorf("expected %v, got %v", 1, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Entries()), "[{1 a}]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	m.Clear()
+
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("expected %v, got %v", true, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.KeySet()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestDefensiveCopies(t *testing.T) {
+	m := New[int, string](2, slicemultimap.New[int, string])
+	m.PutAll(1, []string{"a", "b", "c"})
+
+	values, _ := m.Get(1)
+	values[0] = "z"
+
+	if actualValue, _ := m.Get(1); actualValue[0] != "a" {
+		t.Errorf("expected %v, got %v", "a", actualValue[0])
+	}
+}
+
+// The concurrency tests are meant to be run with the race detector: go test -race ./shardedmultimap
+func TestConcurrentAccess(t *testing.T) {
+	m := New[int, int](8, slicemultimap.New[int, int])
+	goroutines, operations := 8, 1000
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				m.Put(i, g)
+				m.Get(i)
+				m.Contains(i, g)
+				if i%100 == 0 {
+					m.Entries()
+					m.ContainsValue(g)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if actualValue, expectedValue := m.Size(), goroutines*operations; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	for i := 0; i < operations; i++ {
+		if values, _ := m.Get(i); len(values) != goroutines {
+			t.Errorf("key %d: expected %v values, got %v", i, goroutines, len(values))
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return New[string, string](4, slicemultimap.New[string, string])
+	}, multimaptest.List)
+}
+
+func TestIterators(t *testing.T) {
+	m := New[int, string](4, slicemultimap.New[int, string])
+	m.Put(5, "e")
+	m.Put(3, "c")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+	m.PutAll(4, []string{"d", "y"})
+
+	var entries []multimap.Entry[int, string]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[int, string]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keys []int
+	for key := range m.KeysSeq() {
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := keys, m.Keys(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var values []string
+	for value := range m.ValuesSeq() {
+		values = append(values, value)
+	}
+	if actualValue, expectedValue := values, m.Values(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keySet []int
+	for key, values := range m.Sets() {
+		keySet = append(keySet, key)
+		if expectedValue, _ := m.Get(key); !sameElements(values, expectedValue) {
+			t.Errorf("key %d: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+	if actualValue, expectedValue := keySet, m.KeySet(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	count := 0
+	for range m.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	for range m.Sets() {
+		count++
+		break
+	}
+	if count != 4 {
+		t.Errorf("expected %v, got %v", 4, count)
+	}
+
+	m.Clear()
+
+	for key, value := range m.All() {
+		t.Errorf("unexpected entry %v: %v", key, value)
+	}
+}
+
+func TestIterateWhileModifying(t *testing.T) {
+	m := New[int, string](4, slicemultimap.New[int, string])
+	m.PutAll(1, []string{"a", "b"})
+	m.Put(2, "c")
+
+	for key, value := range m.All() {
+		m.Put(key+10, value)
+	}
+	for key := range m.Sets() {
+		m.RemoveAll(key)
+	}
+
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := New[string, int](4, slicemultimap.New[string, int])
+	m.PutAll("a", []int{3, 1, 2})
+	m.Put("b", 4)
+
+	c := m.Clone()
+	if !multimap.Equal[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+	if actualValue, expectedValue := multimap.Hash[string, int](c), multimap.Hash[string, int](m); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	c.Put("a", 5)
+	c.Remove("b", 4)
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.Contains("b", 4) || m.Contains("a", 5) {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+
+	m.RemoveAll("a")
+	if actualValue, _ := c.Get("a"); !sameElements(actualValue, []int{3, 1, 2, 5}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 5}, actualValue)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	m := New[int, string](4, slicemultimap.New[int, string])
+	m.PutAll(2, []string{"b", "a"})
+	m.Put(1, "x")
+	m.Put(3, "")
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := New[int, string](4, slicemultimap.New[int, string])
+	actual.Put(4, "d")
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual = New[int, string](4, slicemultimap.New[int, string])
+	if err := gob.NewDecoder(&buf).Decode(actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if err := actual.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated at %d: expected an error", i)
+		}
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av == bv {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check equality of entries.
+func sameEntries[K comparable, V comparable](a []multimap.Entry[K, V], b []multimap.Entry[K, V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av.Key == bv.Key && av.Value == bv.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Utilities for Benchmarking
+func newMutexMultiMap() multimap.MultiMap[int, int] {
+	return syncmultimap.New[int, int](slicemultimap.New[int, int]())
+}
+
+func newShardedMultiMap() multimap.MultiMap[int, int] {
+	return New[int, int](32, slicemultimap.New[int, int])
+}
+
+func benchmarkParallelPut(b *testing.B, m multimap.MultiMap[int, int], keys int) {
+	var goroutine atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		g := int(goroutine.Add(1))
+		for i := 0; pb.Next(); i++ {
+			m.Put((g*7919+i)%keys, i)
+			if i%keys == 0 {
+				m.RemoveAll((g*7919 + i) % keys)
+			}
+		}
+	})
+}
+
+func benchmarkParallelGet(b *testing.B, m multimap.MultiMap[int, int], keys int) {
+	for n := 0; n < keys; n++ {
+		m.Put(n, n)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			m.Get(i % keys)
+		}
+	})
+}
+
+func benchmarkParallelMixed(b *testing.B, m multimap.MultiMap[int, int], keys int) {
+	var goroutine atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		g := int(goroutine.Add(1))
+		for i := 0; pb.Next(); i++ {
+			key := (g*7919 + i) % keys
+			switch i % 4 {
+			case 0:
+				m.Put(key, i)
+			case 1:
+				m.Remove(key, i-1)
+			default:
+				m.Contains(key, i)
+			}
+		}
+	})
+}
+
+func BenchmarkParallelPutMutex(b *testing.B) {
+	benchmarkParallelPut(b, newMutexMultiMap(), 10000)
+}
+
+func BenchmarkParallelPutSharded(b *testing.B) {
+	benchmarkParallelPut(b, newShardedMultiMap(), 10000)
+}
+
+func BenchmarkParallelGetMutex(b *testing.B) {
+	benchmarkParallelGet(b, newMutexMultiMap(), 10000)
+}
+
+func BenchmarkParallelGetSharded(b *testing.B) {
+	benchmarkParallelGet(b, newShardedMultiMap(), 10000)
+}
+
+func BenchmarkParallelMixedMutex(b *testing.B) {
+	benchmarkParallelMixed(b, newMutexMultiMap(), 10000)
+}
+
+func BenchmarkParallelMixedSharded(b *testing.B) {
+	benchmarkParallelMixed(b, newShardedMultiMap(), 10000)
+}
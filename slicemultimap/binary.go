@@ -0,0 +1,49 @@
+package slicemultimap
+
+import (
+	"encoding"
+	"encoding/gob"
+
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = &MultiMap[any, any]{}
+	_ encoding.BinaryUnmarshaler = &MultiMap[any, any]{}
+	_ gob.GobEncoder             = &MultiMap[any, any]{}
+	_ gob.GobDecoder             = &MultiMap[any, any]{}
+)
+
+// MarshalBinary encodes the multimap into a compact binary form.
+// Every key is written once, followed by the number of its values and the values themselves,
+// so the insertion ordering of values for a given key is preserved.
+func (m *MultiMap[K, V]) MarshalBinary() ([]byte, error) {
+	return codec.AppendMultiMap(nil, len(m.m), m.Sets())
+}
+
+// UnmarshalBinary decodes a multimap encoded by MarshalBinary, replacing all elements of the multimap.
+// Corrupt input yields an error and leaves the multimap unchanged.
+func (m *MultiMap[K, V]) UnmarshalBinary(data []byte) error {
+	sets, err := codec.DecodeMultiMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.Clear()
+	for _, set := range sets {
+		if len(set.Values) > 0 {
+			m.m[set.Key] = append(m.m[set.Key], set.Values...)
+			m.size += len(set.Values)
+		}
+	}
+	return nil
+}
+
+// GobEncode encodes the multimap for encoding/gob using MarshalBinary.
+func (m *MultiMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode decodes the multimap for encoding/gob using UnmarshalBinary.
+func (m *MultiMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
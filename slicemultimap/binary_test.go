@@ -0,0 +1,144 @@
+package slicemultimap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"github.com/rafos/go-multimap/internal/codec"
+
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("b", []int{3, 1, 2, 1})
+	m.Put("a", -5)
+	m.Put("", 0)
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var actual MultiMap[string, int]
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := actual.Get("b"); !sameOrder(actualValue, []int{3, 1, 2, 1}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 1}, actualValue)
+	}
+	if actualValue := actual.Size(); actualValue != 6 {
+		t.Errorf("expected %v, got %v", 6, actualValue)
+	}
+}
+
+func TestMarshalBinaryStructValues(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	m := New[int, point]()
+	m.PutAll(1, []point{{1, 2}, {3, 4}})
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := New[int, point]()
+	actual.Put(2, point{})
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestUnmarshalBinaryCorrupt(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("key", []int{1, 2, 3})
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < len(data); i++ {
+		actual := New[string, int]()
+		actual.Put("x", 1)
+		if err := actual.UnmarshalBinary(data[:i]); !errors.Is(err, codec.ErrCorrupt) {
+			t.Errorf("truncated at %d: expected %v, got %v", i, codec.ErrCorrupt, err)
+		}
+		if actualValue := actual.Size(); actualValue != 1 {
+			t.Errorf("truncated at %d: expected %v, got %v", i, 1, actualValue)
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	type snapshot struct {
+		Name  string
+		Index *MultiMap[string, int]
+	}
+	s := snapshot{Name: "index", Index: New[string, int]()}
+	s.Index.PutAll("a", []int{2, 1})
+	s.Index.Put("b", 3)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var actual snapshot
+	if err := gob.NewDecoder(&buf).Decode(&actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Name, s.Name; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := actual.Index.Entries(), s.Index.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := actual.Index.Get("a"); !sameOrder(actualValue, []int{2, 1}) {
+		t.Errorf("expected %v, got %v", []int{2, 1}, actualValue)
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	m := New[string, int]()
+	m.PutAll("a", []int{1, 1, -2})
+	m.Put("bc", 300)
+	seed, _ := m.MarshalBinary()
+	f.Add(seed)
+	f.Add([]byte{})
+	f.Add([]byte{codec.Version, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		actual := New[string, int]()
+		if err := actual.UnmarshalBinary(data); err != nil {
+			return
+		}
+		size := 0
+		for _, values := range actual.Sets() {
+			if len(values) == 0 {
+				t.Fatalf("unexpected empty key")
+			}
+			size += len(values)
+		}
+		if actualValue := actual.Size(); actualValue != size {
+			t.Fatalf("expected %v, got %v", size, actualValue)
+		}
+		encoded, err := actual.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		again := New[string, int]()
+		if err := again.UnmarshalBinary(encoded); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actualValue, expectedValue := again.Entries(), actual.Entries(); !sameEntries(actualValue, expectedValue) {
+			t.Fatalf("expected %v, got %v", expectedValue, actualValue)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package slicemultimap
+
+import (
+	"iter"
+
+	"github.com/rafos/go-multimap"
+)
+
+// FromEntries instantiates a new multimap holding the given key-value pairs.
+// The values of every key keep the ordering of entries and are allocated at their final size.
+func FromEntries[K comparable, V comparable](entries []multimap.Entry[K, V]) *MultiMap[K, V] {
+	return build(len(entries),
+		func(i int) K { return entries[i].Key },
+		func(i int) V { return entries[i].Value })
+}
+
+// FromSeq instantiates a new multimap holding the key-value pairs of seq, such as the All iterator of another multimap.
+func FromSeq[K comparable, V comparable](seq iter.Seq2[K, V]) *MultiMap[K, V] {
+	m := New[K, V]()
+	for key, value := range seq {
+		m.Put(key, value)
+	}
+	return m
+}
+
+// Index instantiates a new multimap holding every item under the key computed by key.
+// The items of every key keep the ordering of items and are allocated at their final size.
+//
+// This is typically known as Multimaps.index in other languages.
+func Index[T comparable, K comparable](items []T, key func(T) K) *MultiMap[K, T] {
+	return build(len(items),
+		func(i int) K { return key(items[i]) },
+		func(i int) T { return items[i] })
+}
+
+// GroupBy instantiates a new multimap holding the value computed by value for every item,
+// under the key computed by key.
+// The values of every key keep the ordering of items and are allocated at their final size.
+func GroupBy[T any, K comparable, V comparable](items []T, key func(T) K, value func(T) V) *MultiMap[K, V] {
+	return build(len(items),
+		func(i int) K { return key(items[i]) },
+		func(i int) V { return value(items[i]) })
+}
+
+// build instantiates a new multimap holding n key-value pairs.
+// Keys are numbered by first occurrence and counted first, so that the values of all keys
+// are allocated at once and placed without further map lookups.
+func build[K comparable, V comparable](n int, keyAt func(int) K, valueAt func(int) V) *MultiMap[K, V] {
+	groups := make(map[K]int)
+	var keys []K
+	var ends []int
+	ids := make([]int, n)
+	for i := range ids {
+		key := keyAt(i)
+		id, found := groups[key]
+		if !found {
+			id = len(keys)
+			groups[key] = id
+			keys = append(keys, key)
+			ends = append(ends, 0)
+		}
+		ids[i] = id
+		ends[id]++
+	}
+	for id := 1; id < len(ends); id++ {
+		ends[id] += ends[id-1]
+	}
+
+	// Values are placed backwards from the end of their key's range to keep their ordering.
+	values := make([]V, n)
+	for i := n - 1; i >= 0; i-- {
+		ends[ids[i]]--
+		values[ends[ids[i]]] = valueAt(i)
+	}
+
+	m := &MultiMap[K, V]{m: make(map[K][]V, len(keys)), size: n}
+	for id, key := range keys {
+		end := n
+		if id+1 < len(ends) {
+			end = ends[id+1]
+		}
+		// Capping the capacity keeps a later Put from overwriting the values of the next key.
+		m.m[key] = values[ends[id]:end:end]
+	}
+	return m
+}
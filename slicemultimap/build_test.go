@@ -0,0 +1,120 @@
+package slicemultimap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+)
+
+func TestFromEntries(t *testing.T) {
+	entries := []multimap.Entry[string, int]{
+		{Key: "b", Value: 2},
+		{Key: "a", Value: 1},
+		{Key: "b", Value: 1},
+		{Key: "b", Value: 2},
+	}
+	m := FromEntries(entries)
+
+	if actualValue, expectedValue := m.Entries(), entries; !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := m.Get("b"); !sameOrder(actualValue, []int{2, 1, 2}) {
+		t.Errorf("expected %v, got %v", []int{2, 1, 2}, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if actualValue := cap(m.m["b"]); actualValue != 3 {
+		t.Errorf("expected a capacity of %v, got %v", 3, actualValue)
+	}
+
+	if actualValue := FromEntries[string, int](nil); !actualValue.Empty() {
+		t.Errorf("expected an empty multimap, got %v", actualValue.Entries())
+	}
+}
+
+func TestFromSeq(t *testing.T) {
+	src := New[string, int]()
+	src.PutAll("a", []int{1, 1, 2})
+	src.Put("b", 3)
+
+	m := FromSeq(src.All())
+	if !multimap.Equal[string, int](m, src) {
+		t.Errorf("expected %v, got %v", src.Entries(), m.Entries())
+	}
+}
+
+func TestIndex(t *testing.T) {
+	words := []string{"apple", "avocado", "banana", "apple", "cherry", "blueberry"}
+
+	m := Index(words, func(word string) byte { return word[0] })
+	if actualValue, _ := m.Get('a'); !sameOrder(actualValue, []string{"apple", "avocado", "apple"}) {
+		t.Errorf("expected %v, got %v", []string{"apple", "avocado", "apple"}, actualValue)
+	}
+	if actualValue, _ := m.Get('b'); !sameOrder(actualValue, []string{"banana", "blueberry"}) {
+		t.Errorf("expected %v, got %v", []string{"banana", "blueberry"}, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != len(words) {
+		t.Errorf("expected %v, got %v", len(words), actualValue)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	type person struct {
+		name string
+		city string
+	}
+	people := []person{{"Ann", "Oslo"}, {"Bob", "Rome"}, {"Cid", "Oslo"}}
+
+	calls := 0
+	m := GroupBy(people, func(p person) string {
+		calls++
+		return p.city
+	}, func(p person) string { return strings.ToUpper(p.name) })
+
+	if actualValue, _ := m.Get("Oslo"); !sameOrder(actualValue, []string{"ANN", "CID"}) {
+		t.Errorf("expected %v, got %v", []string{"ANN", "CID"}, actualValue)
+	}
+	if actualValue, _ := m.Get("Rome"); !sameOrder(actualValue, []string{"BOB"}) {
+		t.Errorf("expected %v, got %v", []string{"BOB"}, actualValue)
+	}
+	if calls != len(people) {
+		t.Errorf("expected the key function to be called %v times, got %v", len(people), calls)
+	}
+
+	m.Put("Oslo", "DAN")
+	if actualValue, _ := m.Get("Oslo"); !sameOrder(actualValue, []string{"ANN", "CID", "DAN"}) {
+		t.Errorf("expected %v, got %v", []string{"ANN", "CID", "DAN"}, actualValue)
+	}
+	if actualValue, _ := m.Get("Rome"); !sameOrder(actualValue, []string{"BOB"}) {
+		t.Errorf("expected %v, got %v", []string{"BOB"}, actualValue)
+	}
+}
+
+func BenchmarkFromEntries(b *testing.B) {
+	b.StopTimer()
+	entries := make([]multimap.Entry[int, int], 100000)
+	for i := range entries {
+		entries[i] = multimap.Entry[int, int]{Key: i % 1000, Value: i}
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		FromEntries(entries)
+	}
+}
+
+func BenchmarkPutEntries(b *testing.B) {
+	b.StopTimer()
+	entries := make([]multimap.Entry[int, int], 100000)
+	for i := range entries {
+		entries[i] = multimap.Entry[int, int]{Key: i % 1000, Value: i}
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		m := New[int, int]()
+		for _, entry := range entries {
+			m.Put(entry.Key, entry.Value)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package slicemultimap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"reflect"
+
+	"github.com/rafos/go-multimap"
+)
+
+var (
+	_ json.Marshaler   = &MultiMap[any, any]{}
+	_ json.Unmarshaler = &MultiMap[any, any]{}
+)
+
+// MarshalJSON encodes the multimap as a JSON object mapping every key to the array of its values.
+// Keys have to be strings, integers or implement encoding.TextMarshaler to be used as JSON object keys.
+// Multimaps with any other keys are encoded as an array of entries instead,
+// where each entry is an object with a "Key" and a "Value" field.
+// The insertion ordering of values for a given key is preserved by both encodings.
+func (m *MultiMap[K, V]) MarshalJSON() ([]byte, error) {
+	if !objectKeys[K]() {
+		return json.Marshal(m.Entries())
+	}
+	if m.m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m.m)
+}
+
+// UnmarshalJSON decodes a multimap encoded by MarshalJSON, replacing all elements of the multimap.
+// Both the object and the array of entries encodings are accepted regardless of the key type.
+func (m *MultiMap[K, V]) UnmarshalJSON(data []byte) error {
+	values := make(map[K][]V)
+	if data = bytes.TrimLeft(data, " \t\r\n"); len(data) > 0 && data[0] == '[' {
+		var entries []multimap.Entry[K, V]
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			values[entry.Key] = append(values[entry.Key], entry.Value)
+		}
+	} else if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	} else if values == nil {
+		values = make(map[K][]V)
+	}
+
+	size := 0
+	for key, vs := range values {
+		if len(vs) == 0 {
+			delete(values, key)
+		}
+		size += len(vs)
+	}
+	m.m = values
+	m.size = size
+	return nil
+}
+
+// objectKeys returns true if keys of type K can be encoded as JSON object keys.
+func objectKeys[K comparable]() bool {
+	t := reflect.TypeFor[K]()
+	if t.Implements(reflect.TypeFor[encoding.TextMarshaler]()) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
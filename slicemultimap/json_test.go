@@ -0,0 +1,175 @@
+package slicemultimap
+
+import (
+	"encoding/json"
+	"github.com/rafos/go-multimap"
+
+	"testing"
+)
+
+func TestMarshalJSONStringKeys(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("b", []int{3, 1, 2})
+	m.Put("a", 5)
+	m.Put("b", 1)
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := string(data), `{"a":[5],"b":[3,1,2,1]}`; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	actual := New[string, int]()
+	actual.Put("c", 7)
+	if err := json.Unmarshal(data, actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Size(), 5; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := actual.Get("b"); !sameOrder(actualValue, []int{3, 1, 2, 1}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 1}, actualValue)
+	}
+	if actualValue := actual.ContainsKey("c"); actualValue != false {
+		t.Errorf("expected %v, got %v", false, actualValue)
+	}
+}
+
+func TestMarshalJSONIntKeys(t *testing.T) {
+	m := New[int, string]()
+	m.PutAll(10, []string{"x", "a"})
+	m.Put(-2, "b")
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := string(data), `{"-2":["b"],"10":["x","a"]}`; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var actual MultiMap[int, string]
+	if err := json.Unmarshal(data, &actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := actual.Get(10); !sameOrder(actualValue, []string{"x", "a"}) {
+		t.Errorf("expected %v, got %v", []string{"x", "a"}, actualValue)
+	}
+
+	actual.Put(3, "c")
+	if actualValue := actual.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+}
+
+func TestMarshalJSONStructKeys(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	m := New[point, string]()
+	m.PutAll(point{1, 2}, []string{"b", "a", "b"})
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedValue := `[{"Key":{"X":1,"Y":2},"Value":"b"},{"Key":{"X":1,"Y":2},"Value":"a"},{"Key":{"X":1,"Y":2},"Value":"b"}]`
+	if actualValue := string(data); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	m.Put(point{3, 4}, "c")
+	data, err = json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := New[point, string]()
+	if err := json.Unmarshal(data, actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := actual.Get(point{1, 2}); !sameOrder(actualValue, []string{"b", "a", "b"}) {
+		t.Errorf("expected %v, got %v", []string{"b", "a", "b"}, actualValue)
+	}
+	if actualValue := actual.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+}
+
+func TestMarshalJSONEmbedded(t *testing.T) {
+	type config struct {
+		Tags *MultiMap[string, string] `json:"tags"`
+	}
+	c := config{Tags: New[string, string]()}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := string(data), `{"tags":{}}`; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var actual config
+	if err := json.Unmarshal([]byte(`{"tags":{"env":["prod","eu"],"empty":[]}}`), &actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, _ := actual.Tags.Get("env"); !sameOrder(actualValue, []string{"prod", "eu"}) {
+		t.Errorf("expected %v, got %v", []string{"prod", "eu"}, actualValue)
+	}
+	if actualValue := actual.Tags.ContainsKey("empty"); actualValue != false {
+		t.Errorf("expected %v, got %v", false, actualValue)
+	}
+	if actualValue := actual.Tags.Size(); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data            string
+		expectedEntries []multimap.Entry[int, string]
+		expectedError   bool
+	}{
+		{`{}`, nil, false},
+		{`null`, nil, false},
+		{`[]`, nil, false},
+		{` [{"Key":1,"Value":"a"},{"Key":1,"Value":"b"}]`, []multimap.Entry[int, string]{{Key: 1, Value: "a"}, {Key: 1, Value: "b"}}, false},
+		{`{"1":["a"],"2":["b"]}`, []multimap.Entry[int, string]{{Key: 1, Value: "a"}, {Key: 2, Value: "b"}}, false},
+		{`{"x":["a"]}`, nil, true},
+		{`{"1":"a"}`, nil, true},
+		{`[{"Key":"1","Value":"a"}]`, nil, true},
+		{`{"1":["a"]`, nil, true},
+	}
+
+	for i, test := range tests {
+		m := New[int, string]()
+		m.Put(9, "z")
+		err := json.Unmarshal([]byte(test.data), m)
+		if actualError := err != nil; actualError != test.expectedError {
+			t.Errorf("test %d: expected error %v, got %v", i+1, test.expectedError, err)
+			continue
+		}
+		if test.expectedError {
+			continue
+		}
+		if actualValue := m.Entries(); !sameEntries(actualValue, test.expectedEntries) {
+			t.Errorf("test %d: expected %v, got %v", i+1, test.expectedEntries, actualValue)
+		}
+		if actualValue := m.Size(); actualValue != len(test.expectedEntries) {
+			t.Errorf("test %d: expected %v, got %v", i+1, len(test.expectedEntries), actualValue)
+		}
+		m.Put(9, "z")
+		if actualValue := m.Contains(9, "z"); actualValue != true {
+			t.Errorf("test %d: expected %v, got %v", i+1, true, actualValue)
+		}
+	}
+}
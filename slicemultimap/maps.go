@@ -0,0 +1,42 @@
+package slicemultimap
+
+import "slices"
+
+// FromMap instantiates a new multimap holding the values of every key of src.
+// The values are copied, so src can be modified independently of the multimap.
+// Keys of src without values are skipped.
+func FromMap[K comparable, V comparable](src map[K][]V) *MultiMap[K, V] {
+	m := &MultiMap[K, V]{m: make(map[K][]V, len(src))}
+	for key, values := range src {
+		if len(values) == 0 {
+			continue
+		}
+		m.m[key] = slices.Clone(values)
+		m.size += len(values)
+	}
+	return m
+}
+
+// AsMap returns a snapshot of the multimap as a native map from every key to its values.
+// The values are copied, so the map can be modified independently of the multimap.
+func (m *MultiMap[K, V]) AsMap() map[K][]V {
+	result := make(map[K][]V, len(m.m))
+	for key, values := range m.m {
+		result[key] = slices.Clone(values)
+	}
+	return result
+}
+
+// ToSetMap returns a snapshot of the multimap as a native map from every key to the set of its values.
+// Duplicate values of a key are collapsed.
+func (m *MultiMap[K, V]) ToSetMap() map[K]map[V]struct{} {
+	result := make(map[K]map[V]struct{}, len(m.m))
+	for key, values := range m.m {
+		set := make(map[V]struct{}, len(values))
+		for _, value := range values {
+			set[value] = struct{}{}
+		}
+		result[key] = set
+	}
+	return result
+}
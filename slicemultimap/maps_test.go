@@ -0,0 +1,85 @@
+package slicemultimap
+
+import "testing"
+
+func TestFromMap(t *testing.T) {
+	src := map[string][]int{"a": {3, 1, 3}, "b": {2}, "c": {}, "d": nil}
+	m := FromMap(src)
+
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []string{"a", "b"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := m.Get("a"); !sameOrder(actualValue, []int{3, 1, 3}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 3}, actualValue)
+	}
+
+	src["a"][0] = 9
+	src["b"] = append(src["b"], 4)
+	if actualValue, _ := m.Get("a"); !sameOrder(actualValue, []int{3, 1, 3}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 3}, actualValue)
+	}
+	if actualValue, _ := m.Get("b"); !sameOrder(actualValue, []int{2}) {
+		t.Errorf("expected %v, got %v", []int{2}, actualValue)
+	}
+
+	if actualValue := FromMap[string, int](nil); !actualValue.Empty() {
+		t.Errorf("expected an empty multimap, got %v", actualValue.Entries())
+	}
+}
+
+func TestAsMap(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("a", []int{3, 1, 3})
+	m.Put("b", 2)
+
+	result := m.AsMap()
+	if actualValue := len(result); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+	if actualValue := result["a"]; !sameOrder(actualValue, []int{3, 1, 3}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 3}, actualValue)
+	}
+
+	result["a"][0] = 9
+	result["b"] = append(result["b"], 4)
+	delete(result, "a")
+	if actualValue, _ := m.Get("a"); !sameOrder(actualValue, []int{3, 1, 3}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 3}, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+
+	m.Put("a", 5)
+	if _, found := result["a"]; found {
+		t.Errorf("expected the snapshot to be unaffected by changes of the multimap")
+	}
+
+	if actualValue := FromMap(m.AsMap()); !sameEntries(actualValue.Entries(), m.Entries()) {
+		t.Errorf("expected %v, got %v", m.Entries(), actualValue.Entries())
+	}
+}
+
+func TestToSetMap(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("a", []int{3, 1, 3})
+	m.Put("b", 2)
+
+	result := m.ToSetMap()
+	if actualValue := len(result["a"]); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+	for _, value := range []int{1, 3} {
+		if _, found := result["a"][value]; !found {
+			t.Errorf("expected %v to be contained", value)
+		}
+	}
+
+	delete(result["b"], 2)
+	if !m.Contains("b", 2) {
+		t.Errorf("expected the multimap to be unaffected by changes of the snapshot")
+	}
+}
@@ -10,13 +10,19 @@
 // Structure is not thread safe.
 package slicemultimap
 
-import "github.com/rafos/go-multimap"
+import (
+	"iter"
+	"slices"
+
+	"github.com/rafos/go-multimap"
+)
 
 var _ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
 
 // MultiMap holds the elements in go's native map.
 type MultiMap[K comparable, V comparable] struct {
-	m map[K][]V
+	m    map[K][]V
+	size int
 }
 
 // New instantiates a new multimap.
@@ -24,17 +30,53 @@ func New[K comparable, V comparable]() *MultiMap[K, V] {
 	return &MultiMap[K, V]{m: make(map[K][]V)}
 }
 
+// View is a read-only view of the values associated with a key.
+// It is not affected by later modifications of the multimap.
+type View[V comparable] struct {
+	values []V
+}
+
+// Len returns the number of values in the view.
+func (v View[V]) Len() int {
+	return len(v.values)
+}
+
+// At returns the value at index i of the view.
+// It panics if i is out of range.
+func (v View[V]) At(i int) V {
+	return v.values[i]
+}
+
+// Range calls f sequentially for each index and value in the view.
+// If f returns false, range stops the iteration.
+func (v View[V]) Range(f func(i int, value V) bool) {
+	for i, value := range v.values {
+		if !f(i, value) {
+			return
+		}
+	}
+}
+
 // Get searches the element in the multimap by key.
-// It returns its value or nil if key is not found in multimap.
+// It returns a copy of its values or nil if key is not found in multimap.
 // Second return parameter is true if key was found, otherwise false.
 func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
 	values, found = m.m[key]
-	return
+	return slices.Clone(values), found
+}
+
+// GetView searches the element in the multimap by key without copying its values.
+// It returns a read-only view of its values, which is empty if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) GetView(key K) (view View[V], found bool) {
+	values, found := m.m[key]
+	return View[V]{values: values}, found
 }
 
 // Put stores a key-value pair in this multimap.
 func (m *MultiMap[K, V]) Put(key K, value V) {
 	m.m[key] = append(m.m[key], value)
+	m.size++
 }
 
 // PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
@@ -62,7 +104,7 @@ func (m *MultiMap[K, V]) ContainsKey(key K) (found bool) {
 }
 
 // ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
-func (m *MultiMap[K, V]) ContainsValue(value interface{}) bool {
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
 	for _, values := range m.m {
 		for _, v := range values {
 			if v == value {
@@ -74,43 +116,93 @@ func (m *MultiMap[K, V]) ContainsValue(value interface{}) bool {
 }
 
 // Remove removes a single key-value pair from this multimap, if such exists.
+// When the key holds the value several times, its first occurrence is removed.
 func (m *MultiMap[K, V]) Remove(key K, value V) {
+	m.RemoveFirst(key, value)
+}
+
+// RemoveFirst removes the first occurrence of the key-value pair from this multimap, if such exists.
+// It returns the number of key-value pairs removed, which is 1 or 0.
+func (m *MultiMap[K, V]) RemoveFirst(key K, value V) int {
+	values := m.m[key]
+	for i, v := range values {
+		if v == value {
+			m.removeAt(key, values, i)
+			return 1
+		}
+	}
+	return 0
+}
+
+// RemoveLast removes the last occurrence of the key-value pair from this multimap, if such exists.
+// It returns the number of key-value pairs removed, which is 1 or 0.
+func (m *MultiMap[K, V]) RemoveLast(key K, value V) int {
+	values := m.m[key]
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] == value {
+			m.removeAt(key, values, i)
+			return 1
+		}
+	}
+	return 0
+}
+
+// RemoveEvery removes every occurrence of the key-value pair from this multimap.
+// It returns the number of key-value pairs removed.
+func (m *MultiMap[K, V]) RemoveEvery(key K, value V) int {
 	values, found := m.m[key]
-	if found {
-		for i, v := range values {
-			if v == value {
-				m.m[key] = append(values[:i], values[i+1:]...)
-			}
+	if !found {
+		return 0
+	}
+	kept := make([]V, 0, len(values))
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
 		}
 	}
-	if len(m.m[key]) == 0 {
+	removed := len(values) - len(kept)
+	m.size -= removed
+	switch {
+	case len(kept) == 0:
 		delete(m.m, key)
+	case removed > 0:
+		m.m[key] = kept
 	}
+	return removed
+}
+
+// removeAt removes the value at index i from the values of the key.
+func (m *MultiMap[K, V]) removeAt(key K, values []V, i int) {
+	m.size--
+	if len(values) == 1 {
+		delete(m.m, key)
+		return
+	}
+	// Values are copied rather than shifted in place,
+	// so views handed out by GetView are left untouched.
+	m.m[key] = append(values[:i:i], values[i+1:]...)
 }
 
 // RemoveAll removes all values associated with the key from the multimap.
 func (m *MultiMap[K, V]) RemoveAll(key K) {
+	m.size -= len(m.m[key])
 	delete(m.m, key)
 }
 
 // Empty returns true if multimap does not contain any key-value pairs.
 func (m *MultiMap[K, V]) Empty() bool {
-	return m.Size() == 0
+	return m.size == 0
 }
 
 // Size returns number of key-value pairs in the multimap.
 func (m *MultiMap[K, V]) Size() int {
-	size := 0
-	for _, value := range m.m {
-		size += len(value)
-	}
-	return size
+	return m.size
 }
 
 // Keys returns a view collection containing the key from each key-value pair in this multimap.
 // This is done without collapsing duplicates.
 func (m *MultiMap[K, V]) Keys() []K {
-	keys := make([]K, m.Size())
+	keys := make([]K, m.size)
 	count := 0
 	for key, value := range m.m {
 		for range value {
@@ -135,7 +227,7 @@ func (m *MultiMap[K, V]) KeySet() []K {
 // Values returns all values from each key-value pair contained in this multimap.
 // This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
 func (m *MultiMap[K, V]) Values() []V {
-	values := make([]V, m.Size())
+	values := make([]V, m.size)
 	count := 0
 	for _, vs := range m.m {
 		for _, value := range vs {
@@ -153,7 +245,7 @@ func (m *MultiMap[K, V]) Values() []V {
 //   - var key = entry.Key
 //   - var value = entry.Value
 func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
-	entries := make([]multimap.Entry[K, V], m.Size())
+	entries := make([]multimap.Entry[K, V], m.size)
 	count := 0
 	for key, values := range m.m {
 		for _, value := range values {
@@ -164,7 +256,75 @@ func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
 	return entries
 }
 
+// All returns an iterator over all key-value pairs contained in this multimap.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, values := range m.m {
+			for _, value := range values {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key, values := range m.m {
+			for range values {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, values := range m.m {
+			for _, value := range values {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over all distinct keys together with their values.
+// The yielded values are not copied and must not be modified.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for key, values := range m.m {
+			if !yield(key, values[:len(values):len(values)]) {
+				return
+			}
+		}
+	}
+}
+
 // Clear removes all elements from the map.
 func (m *MultiMap[K, V]) Clear() {
 	m.m = make(map[K][]V)
+	m.size = 0
+}
+
+// Clone returns a deep copy of the multimap.
+// The values of every key are copied, so the clone and the multimap can be modified independently.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	c := &MultiMap[K, V]{m: make(map[K][]V, len(m.m)), size: m.size}
+	for key, values := range m.m {
+		c.m[key] = slices.Clone(values)
+	}
+	return c
 }
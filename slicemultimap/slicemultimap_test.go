@@ -2,9 +2,11 @@ package slicemultimap
 
 import (
 	"fmt"
-	"github.com/rafos/go-multimap"
-
+	"sort"
 	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
 )
 
 func TestClear(t *testing.T) {
@@ -270,6 +272,52 @@ func TestRemove(t *testing.T) {
 	}
 }
 
+func TestRemoveDuplicates(t *testing.T) {
+	tests := []struct {
+		name            string
+		values          []string
+		remove          func(m *MultiMap[int, string]) int
+		expectedRemoved int
+		expectedValues  []string
+	}{
+		{"Remove first of two", []string{"a", "a", "b"}, func(m *MultiMap[int, string]) int { size := m.Size(); m.Remove(1, "a"); return size - m.Size() }, 1, []string{"a", "b"}},
+		{"Remove missing", []string{"a", "b"}, func(m *MultiMap[int, string]) int { size := m.Size(); m.Remove(1, "c"); return size - m.Size() }, 0, []string{"a", "b"}},
+		{"Remove only value", []string{"a"}, func(m *MultiMap[int, string]) int { size := m.Size(); m.Remove(1, "a"); return size - m.Size() }, 1, nil},
+		{"RemoveFirst adjacent", []string{"b", "a", "a", "c"}, func(m *MultiMap[int, string]) int { return m.RemoveFirst(1, "a") }, 1, []string{"b", "a", "c"}},
+		{"RemoveFirst spread", []string{"a", "b", "a", "c", "a"}, func(m *MultiMap[int, string]) int { return m.RemoveFirst(1, "a") }, 1, []string{"b", "a", "c", "a"}},
+		{"RemoveFirst missing", []string{"a"}, func(m *MultiMap[int, string]) int { return m.RemoveFirst(1, "b") }, 0, []string{"a"}},
+		{"RemoveFirst missing key", nil, func(m *MultiMap[int, string]) int { return m.RemoveFirst(1, "a") }, 0, nil},
+		{"RemoveLast adjacent", []string{"b", "a", "a", "c"}, func(m *MultiMap[int, string]) int { return m.RemoveLast(1, "a") }, 1, []string{"b", "a", "c"}},
+		{"RemoveLast spread", []string{"a", "b", "a", "c", "a"}, func(m *MultiMap[int, string]) int { return m.RemoveLast(1, "a") }, 1, []string{"a", "b", "a", "c"}},
+		{"RemoveLast only value", []string{"a"}, func(m *MultiMap[int, string]) int { return m.RemoveLast(1, "a") }, 1, nil},
+		{"RemoveLast missing", []string{"a"}, func(m *MultiMap[int, string]) int { return m.RemoveLast(1, "b") }, 0, []string{"a"}},
+		{"RemoveEvery adjacent", []string{"a", "a", "b", "a", "a"}, func(m *MultiMap[int, string]) int { return m.RemoveEvery(1, "a") }, 4, []string{"b"}},
+		{"RemoveEvery all", []string{"a", "a", "a"}, func(m *MultiMap[int, string]) int { return m.RemoveEvery(1, "a") }, 3, nil},
+		{"RemoveEvery missing", []string{"a", "b"}, func(m *MultiMap[int, string]) int { return m.RemoveEvery(1, "c") }, 0, []string{"a", "b"}},
+		{"RemoveEvery missing key", nil, func(m *MultiMap[int, string]) int { return m.RemoveEvery(1, "a") }, 0, nil},
+	}
+
+	for _, test := range tests {
+		m := New[int, string]()
+		m.PutAll(1, test.values)
+		m.Put(2, "a")
+
+		if actualValue := test.remove(m); actualValue != test.expectedRemoved {
+			t.Errorf("%s: expected %v removed, got %v", test.name, test.expectedRemoved, actualValue)
+		}
+		actualValue, actualFound := m.Get(1)
+		if !sameOrder(actualValue, test.expectedValues) || actualFound != (test.expectedValues != nil) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expectedValues, actualValue)
+		}
+		if actualValue, expectedValue := m.Size(), len(test.expectedValues)+1; actualValue != expectedValue {
+			t.Errorf("%s: expected size %v, got %v", test.name, expectedValue, actualValue)
+		}
+		if actualValue, _ := m.Get(2); !sameOrder(actualValue, []string{"a"}) {
+			t.Errorf("%s: expected %v, got %v", test.name, []string{"a"}, actualValue)
+		}
+	}
+}
+
 func TestRemoveAll(t *testing.T) {
 	m := New[int, string]()
 	m.Put(5, "e")
@@ -336,6 +384,191 @@ func TestRemoveAll(t *testing.T) {
 	}
 }
 
+func TestGetCopy(t *testing.T) {
+	m := New[int, string]()
+	m.PutAll(1, []string{"c", "a", "b"})
+
+	values, _ := m.Get(1)
+	values[0] = "z"
+	sort.Strings(values)
+	_ = append(values[:1], "y")
+
+	if actualValue, _ := m.Get(1); !sameOrder(actualValue, []string{"c", "a", "b"}) {
+		t.Errorf("expected %v, got %v", []string{"c", "a", "b"}, actualValue)
+	}
+
+	values, _ = m.Get(1)
+	m.Remove(1, "c")
+	m.Put(1, "d")
+
+	if !sameOrder(values, []string{"c", "a", "b"}) {
+		t.Errorf("expected %v, got %v", []string{"c", "a", "b"}, values)
+	}
+	if actualValue, _ := m.Get(1); !sameOrder(actualValue, []string{"a", "b", "d"}) {
+		t.Errorf("expected %v, got %v", []string{"a", "b", "d"}, actualValue)
+	}
+}
+
+func TestGetView(t *testing.T) {
+	m := New[int, string]()
+	m.PutAll(1, []string{"a", "b", "c", "d"})
+
+	view, found := m.GetView(1)
+	if !found {
+		t.Errorf("expected %v, got %v", true, found)
+	}
+	if actualValue := view.Len(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+
+	m.Remove(1, "a")
+	m.Remove(1, "c")
+	m.Put(1, "e")
+	m.Put(1, "f")
+	m.RemoveAll(2)
+
+	var values []string
+	view.Range(func(i int, value string) bool {
+		if value != view.At(i) {
+			t.Errorf("expected %v, got %v", view.At(i), value)
+		}
+		values = append(values, value)
+		return true
+	})
+	if !sameOrder(values, []string{"a", "b", "c", "d"}) {
+		t.Errorf("expected %v, got %v", []string{"a", "b", "c", "d"}, values)
+	}
+	if actualValue, _ := m.GetView(1); actualValue.Len() != 4 || actualValue.At(0) != "b" || actualValue.At(3) != "f" {
+		t.Errorf("expected %v, got %v", "[b d e f]", actualValue.values)
+	}
+
+	count := 0
+	view.Range(func(i int, value string) bool {
+		count++
+		return i < 1
+	})
+	if count != 2 {
+		t.Errorf("expected %v, got %v", 2, count)
+	}
+
+	view, found = m.GetView(2)
+	if found || view.Len() != 0 {
+		t.Errorf("expected an empty view, got %v", view.values)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := New[string, int]()
+	m.PutAll("a", []int{3, 1, 2})
+	m.Put("b", 4)
+
+	c := m.Clone()
+	if !multimap.Equal[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+	if actualValue, expectedValue := multimap.Hash[string, int](c), multimap.Hash[string, int](m); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	c.Put("a", 5)
+	c.Remove("b", 4)
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.Contains("b", 4) || m.Contains("a", 5) {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+
+	m.RemoveAll("a")
+	if actualValue, _ := c.Get("a"); !sameOrder(actualValue, []int{3, 1, 2, 5}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 5}, actualValue)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return New[string, string]()
+	}, multimaptest.List)
+}
+
+func TestModel(t *testing.T) {
+	multimaptest.RunModel(t, func() multimap.MultiMap[string, string] {
+		return New[string, string]()
+	}, multimaptest.List)
+}
+
+func FuzzModel(f *testing.F) {
+	multimaptest.FuzzModel(f, func() multimap.MultiMap[string, string] {
+		return New[string, string]()
+	}, multimaptest.List)
+}
+
+func TestIterators(t *testing.T) {
+	m := New[int, string]()
+	m.Put(5, "e")
+	m.Put(3, "c")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+	m.PutAll(4, []string{"d", "y"})
+
+	var entries []multimap.Entry[int, string]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[int, string]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keys []int
+	for key := range m.KeysSeq() {
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := keys, m.Keys(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var values []string
+	for value := range m.ValuesSeq() {
+		values = append(values, value)
+	}
+	if actualValue, expectedValue := values, m.Values(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keySet []int
+	for key, values := range m.Sets() {
+		keySet = append(keySet, key)
+		if expectedValue, _ := m.Get(key); !sameElements(values, expectedValue) {
+			t.Errorf("key %d: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+	if actualValue, expectedValue := keySet, m.KeySet(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	count := 0
+	for range m.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	for range m.Sets() {
+		count++
+		break
+	}
+	if count != 4 {
+		t.Errorf("expected %v, got %v", 4, count)
+	}
+
+	m.Clear()
+
+	for key, value := range m.All() {
+		t.Errorf("unexpected entry %v: %v", key, value)
+	}
+}
+
 // Helper function to check equality of keys/values.
 func sameElements[V comparable](a []V, b []V) bool {
 	if len(a) != len(b) {
@@ -356,6 +589,19 @@ func sameElements[V comparable](a []V, b []V) bool {
 	return true
 }
 
+// Helper function to check equality of elements including their order.
+func sameOrder[E comparable](a []E, b []E) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Helper function to check equality of entries.
 func sameEntries[K comparable, V comparable](a []multimap.Entry[K, V], b []multimap.Entry[K, V]) bool {
 	if len(a) != len(b) {
@@ -385,6 +631,54 @@ func benchmarkGet(b *testing.B, m *MultiMap[any, any], size int) {
 	}
 }
 
+func benchmarkGetView(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.GetView(n)
+		}
+	}
+}
+
+func benchmarkSize(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Size()
+		}
+	}
+}
+
+func benchmarkEmpty(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Empty()
+		}
+	}
+}
+
+func benchmarkAll(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for key, value := range m.All() {
+			_, _ = key, value
+		}
+	}
+}
+
+func benchmarkEntries(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for _, entry := range m.Entries() {
+			_ = entry
+		}
+	}
+}
+
+func benchmarkSets(b *testing.B, m *MultiMap[any, any], size int) {
+	for i := 0; i < b.N; i++ {
+		for key, values := range m.Sets() {
+			_, _ = key, values
+		}
+	}
+}
+
 func benchmarkPut(b *testing.B, m *MultiMap[any, any], size int) {
 	for i := 0; i < b.N; i++ {
 		for n := 0; n < size; n++ {
@@ -463,6 +757,270 @@ func BenchmarkMultiMapGet100000(b *testing.B) {
 	benchmarkGet(b, m, size)
 }
 
+func BenchmarkMultiMapGetView100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGetView(b, m, size)
+}
+
+func BenchmarkMultiMapGetView1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGetView(b, m, size)
+}
+
+func BenchmarkMultiMapGetView10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGetView(b, m, size)
+}
+
+func BenchmarkMultiMapGetView100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGetView(b, m, size)
+}
+
+func BenchmarkMultiMapSize100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSize(b, m, size)
+}
+
+func BenchmarkMultiMapSize1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSize(b, m, size)
+}
+
+func BenchmarkMultiMapSize10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSize(b, m, size)
+}
+
+func BenchmarkMultiMapSize100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSize(b, m, size)
+}
+
+func BenchmarkMultiMapEmpty100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEmpty(b, m, size)
+}
+
+func BenchmarkMultiMapEmpty1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEmpty(b, m, size)
+}
+
+func BenchmarkMultiMapEmpty10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEmpty(b, m, size)
+}
+
+func BenchmarkMultiMapEmpty100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEmpty(b, m, size)
+}
+
+func BenchmarkMultiMapAll100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkAll(b, m, size)
+}
+
+func BenchmarkMultiMapAll1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkAll(b, m, size)
+}
+
+func BenchmarkMultiMapAll10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkAll(b, m, size)
+}
+
+func BenchmarkMultiMapAll100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkAll(b, m, size)
+}
+
+func BenchmarkMultiMapEntries100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEntries(b, m, size)
+}
+
+func BenchmarkMultiMapEntries1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEntries(b, m, size)
+}
+
+func BenchmarkMultiMapEntries10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEntries(b, m, size)
+}
+
+func BenchmarkMultiMapEntries100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkEntries(b, m, size)
+}
+
+func BenchmarkMultiMapSets100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSets(b, m, size)
+}
+
+func BenchmarkMultiMapSets1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSets(b, m, size)
+}
+
+func BenchmarkMultiMapSets10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSets(b, m, size)
+}
+
+func BenchmarkMultiMapSets100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := New[any, any]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkSets(b, m, size)
+}
+
 func BenchmarkMultiMapPut100(b *testing.B) {
 	b.StopTimer()
 	size := 100
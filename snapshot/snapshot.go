@@ -0,0 +1,350 @@
+// Package snapshot streams the entries of a multimap to and from an io.Writer and io.Reader.
+//
+// Unlike encoding a whole multimap with MarshalBinary, a snapshot is written and read
+// incrementally, so neither side needs to hold an encoded copy of the multimap in memory.
+//
+// A snapshot starts with a header holding a magic string and the format version,
+// followed by blocks of entries and a final end block:
+//
+//	header:  "MMSN" version(1 byte)
+//	block:   type(1 byte) length(4 bytes) crc32c(4 bytes) payload(length bytes)
+//
+// An entries block carries a uvarint number of entries followed by the key and value of each entry.
+// The end block carries the uvarint total number of entries written. Lengths are little-endian
+// and checksums are CRC-32 (Castagnoli) of the payload.
+//
+// A Reader puts the entries of a block into the multimap only once the whole block has been read
+// and verified. When the stream ends before the end block, the Reader reports ErrTruncated and
+// keeps the partially read block, so reading can be resumed once more data is available,
+// or from another reader positioned at Offset.
+package snapshot
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+// Version is the version of the snapshot format written by Writer.
+const Version = 1
+
+const (
+	magic = "MMSN"
+
+	headerSize      = len(magic) + 1
+	blockHeaderSize = 1 + 4 + 4
+
+	blockEntries byte = 1
+	blockEnd     byte = 2
+
+	// flushSize is the payload size after which the Writer writes a block.
+	flushSize = 64 << 10
+	// maxBlockSize is the largest payload accepted by the Reader.
+	maxBlockSize = 256 << 20
+	// readSize is the amount of data the Reader requests from the underlying reader at once.
+	readSize = 32 << 10
+)
+
+var (
+	// ErrFormat is returned when the stream is not a valid snapshot.
+	ErrFormat = errors.New("snapshot: invalid format")
+	// ErrChecksum is returned when a block does not match its checksum.
+	ErrChecksum = errors.New("snapshot: checksum mismatch")
+	// ErrTruncated is returned when the stream ends before the end of the snapshot.
+	ErrTruncated = fmt.Errorf("snapshot: truncated stream: %w", io.ErrUnexpectedEOF)
+	// ErrClosed is returned when writing to a closed Writer.
+	ErrClosed = errors.New("snapshot: writer closed")
+)
+
+var table = crc32.MakeTable(crc32.Castagnoli)
+
+// Write streams all entries of the multimap m to w as a complete snapshot.
+func Write[K comparable, V any](w io.Writer, m multimap.MultiMap[K, V]) error {
+	sw := NewWriter[K, V](w)
+	if err := sw.WriteAll(m); err != nil {
+		return err
+	}
+	return sw.Close()
+}
+
+// Read puts all entries of the complete snapshot read from r into the multimap m.
+func Read[K comparable, V any](r io.Reader, m multimap.MultiMap[K, V]) error {
+	_, err := NewReader[K, V](r).ReadInto(m)
+	return err
+}
+
+// Writer streams entries to an io.Writer.
+// Entries are buffered and written in blocks, Close has to be called to complete the snapshot.
+type Writer[K comparable, V any] struct {
+	w      io.Writer
+	keys   codec.Codec[K]
+	values codec.Codec[V]
+
+	header  bool
+	payload []byte
+	pending int
+	total   int
+	closed  bool
+	err     error
+}
+
+// NewWriter instantiates a new Writer writing a snapshot to w.
+func NewWriter[K comparable, V any](w io.Writer) *Writer[K, V] {
+	return &Writer[K, V]{w: w, keys: codec.For[K](), values: codec.For[V]()}
+}
+
+// Write adds a key-value pair to the snapshot.
+func (w *Writer[K, V]) Write(key K, value V) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return ErrClosed
+	}
+	payload, err := w.keys.Append(w.payload, key)
+	if err == nil {
+		payload, err = w.values.Append(payload, value)
+	}
+	if err != nil {
+		return err
+	}
+	w.payload = payload
+	w.pending++
+	w.total++
+	if len(w.payload) >= flushSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// WriteAll adds all key-value pairs of the multimap m to the snapshot.
+func (w *Writer[K, V]) WriteAll(m multimap.MultiMap[K, V]) error {
+	for key, value := range m.All() {
+		if err := w.Write(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes all buffered entries to the underlying writer.
+func (w *Writer[K, V]) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if w.pending == 0 {
+		return nil
+	}
+	payload := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(w.payload)), uint64(w.pending))
+	payload = append(payload, w.payload...)
+	if err := w.writeBlock(blockEntries, payload); err != nil {
+		return err
+	}
+	w.payload = w.payload[:0]
+	w.pending = 0
+	return nil
+}
+
+// Close writes all buffered entries followed by the end of the snapshot.
+// It does not close the underlying writer.
+func (w *Writer[K, V]) Close() error {
+	if w.closed {
+		return w.err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+	return w.writeBlock(blockEnd, binary.AppendUvarint(nil, uint64(w.total)))
+}
+
+func (w *Writer[K, V]) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	if _, err := w.w.Write(append([]byte(magic), Version)); err != nil {
+		w.err = err
+		return err
+	}
+	w.header = true
+	return nil
+}
+
+func (w *Writer[K, V]) writeBlock(kind byte, payload []byte) error {
+	header := make([]byte, blockHeaderSize)
+	header[0] = kind
+	binary.LittleEndian.PutUint32(header[1:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(header[5:], crc32.Checksum(payload, table))
+	if _, err := w.w.Write(header); err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.w.Write(payload); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// Reader reads entries of a snapshot from an io.Reader.
+type Reader[K comparable, V any] struct {
+	r      io.Reader
+	keys   codec.Codec[K]
+	values codec.Codec[V]
+
+	buf    []byte
+	offset int64
+	header bool
+	total  int
+	done   bool
+	err    error
+}
+
+// NewReader instantiates a new Reader reading a snapshot from r.
+func NewReader[K comparable, V any](r io.Reader) *Reader[K, V] {
+	return &Reader[K, V]{r: r, keys: codec.For[K](), values: codec.For[V]()}
+}
+
+// Offset returns the number of bytes of the stream that have been completely processed,
+// which is the end of the header or of the last block put into the multimap.
+func (r *Reader[K, V]) Offset() int64 {
+	return r.offset
+}
+
+// Resume continues reading from rd, which has to be positioned at Offset of the original stream.
+// Data of a partially read block is discarded and read again from rd.
+func (r *Reader[K, V]) Resume(rd io.Reader) {
+	r.r = rd
+	r.buf = r.buf[:0]
+}
+
+// ReadInto reads blocks of entries from the stream and puts them into the multimap m
+// until the end of the snapshot is reached. It returns the number of entries put.
+//
+// When the stream ends before the end of the snapshot, ErrTruncated is returned after all
+// complete blocks have been put. Calling ReadInto again continues with the partially read block.
+// Errors of the underlying reader are returned as is and may be retried the same way.
+// ErrFormat and ErrChecksum are permanent and returned by every subsequent call.
+func (r *Reader[K, V]) ReadInto(m multimap.MultiMap[K, V]) (n int, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if !r.header {
+		if err := r.fill(headerSize); err != nil {
+			return 0, err
+		}
+		if string(r.buf[:len(magic)]) != magic {
+			return 0, r.fail(fmt.Errorf("%w: missing header", ErrFormat))
+		}
+		if version := r.buf[len(magic)]; version != Version {
+			return 0, r.fail(fmt.Errorf("%w: unsupported version %d", ErrFormat, version))
+		}
+		r.consume(headerSize)
+		r.header = true
+	}
+
+	for !r.done {
+		if err := r.fill(blockHeaderSize); err != nil {
+			return n, err
+		}
+		kind := r.buf[0]
+		length := binary.LittleEndian.Uint32(r.buf[1:])
+		checksum := binary.LittleEndian.Uint32(r.buf[5:])
+		if length > maxBlockSize {
+			return n, r.fail(fmt.Errorf("%w: block of %d bytes", ErrFormat, length))
+		}
+		if err := r.fill(blockHeaderSize + int(length)); err != nil {
+			return n, err
+		}
+		payload := r.buf[blockHeaderSize : blockHeaderSize+int(length)]
+		if crc32.Checksum(payload, table) != checksum {
+			return n, r.fail(ErrChecksum)
+		}
+
+		switch kind {
+		case blockEntries:
+			entries, err := r.decodeEntries(payload)
+			if err != nil {
+				return n, r.fail(err)
+			}
+			for _, entry := range entries {
+				m.Put(entry.Key, entry.Value)
+			}
+			n += len(entries)
+			r.total += len(entries)
+		case blockEnd:
+			total, size := binary.Uvarint(payload)
+			if size <= 0 || size != len(payload) || total != uint64(r.total) {
+				return n, r.fail(fmt.Errorf("%w: invalid end of snapshot", ErrFormat))
+			}
+			r.done = true
+		default:
+			return n, r.fail(fmt.Errorf("%w: unknown block type %d", ErrFormat, kind))
+		}
+		r.consume(blockHeaderSize + int(length))
+	}
+	return n, nil
+}
+
+// decodeEntries decodes all entries of an entries block.
+func (r *Reader[K, V]) decodeEntries(payload []byte) ([]multimap.Entry[K, V], error) {
+	count, payload, err := codec.ReadCount(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+	entries := make([]multimap.Entry[K, V], count)
+	for i := range entries {
+		if entries[i].Key, payload, err = r.keys.Read(payload); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormat, err)
+		}
+		if entries[i].Value, payload, err = r.values.Read(payload); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFormat, err)
+		}
+	}
+	if len(payload) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes in block", ErrFormat, len(payload))
+	}
+	return entries, nil
+}
+
+// fill reads from the underlying reader until at least n bytes are buffered.
+func (r *Reader[K, V]) fill(n int) error {
+	for len(r.buf) < n {
+		if cap(r.buf)-len(r.buf) < readSize {
+			buf := make([]byte, len(r.buf), max(2*cap(r.buf), n, len(r.buf)+readSize))
+			copy(buf, r.buf)
+			r.buf = buf
+		}
+		read, err := r.r.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf)+read]
+		switch {
+		case len(r.buf) >= n:
+			return nil
+		case err == io.EOF:
+			return ErrTruncated
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
+
+// consume drops the first n buffered bytes, which have been completely processed.
+func (r *Reader[K, V]) consume(n int) {
+	r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+	r.offset += int64(n)
+}
+
+// fail records a permanent error.
+func (r *Reader[K, V]) fail(err error) error {
+	r.err = err
+	return err
+}
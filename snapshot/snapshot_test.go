@@ -0,0 +1,325 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+)
+
+func TestWriteRead(t *testing.T) {
+	m := slicemultimap.New[string, int]()
+	m.PutAll("a", []int{3, 1, 3})
+	m.Put("b", -2)
+	m.Put("", 0)
+
+	var buf bytes.Buffer
+	if err := Write[string, int](&buf, m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := slicemultimap.New[string, int]()
+	if err := Read[string, int](&buf, actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := actual.Get("a"); !sameOrder(actualValue, []int{3, 1, 3}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 3}, actualValue)
+	}
+}
+
+func TestWriteReadEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write[string, int](&buf, slicemultimap.New[string, int]()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := slicemultimap.New[string, int]()
+	if err := Read[string, int](&buf, actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue := actual.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+}
+
+func TestManyBlocks(t *testing.T) {
+	m := slicemultimap.New[int, string]()
+	for i := 0; i < 50000; i++ {
+		m.Put(i%1000, fmt.Sprintf("value-%d", i))
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter[int, string](&buf)
+	if err := w.WriteAll(m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Errorf("expected blocks to be written before Close")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Write(1, "x"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, got %v", ErrClosed, err)
+	}
+
+	actual := slicemultimap.New[int, string]()
+	n, err := NewReader[int, string](&buf).ReadInto(actual)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 50000 {
+		t.Errorf("expected %v, got %v", 50000, n)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v entries, got %v", len(expectedValue), len(actualValue))
+	}
+}
+
+func TestResumeTruncated(t *testing.T) {
+	data := snapshot(t, 20000)
+
+	// The stream grows in small pieces, each read ending with a truncated stream.
+	stream := &growingReader{}
+	r := NewReader[int, string](stream)
+	actual := slicemultimap.New[int, string]()
+	total := 0
+	for offset := 0; ; offset += 1000 {
+		stream.data = data[:min(offset, len(data))]
+		n, err := r.ReadInto(actual)
+		total += n
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrTruncated) || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected %v, got %v", ErrTruncated, err)
+		}
+		if actualValue := actual.Size(); actualValue != total {
+			t.Fatalf("expected %v, got %v", total, actualValue)
+		}
+		if offset > len(data) {
+			t.Fatalf("expected the snapshot to be complete")
+		}
+	}
+	if actualValue := actual.Size(); actualValue != 20000 {
+		t.Errorf("expected %v, got %v", 20000, actualValue)
+	}
+	if r.Offset() != int64(len(data)) {
+		t.Errorf("expected %v, got %v", len(data), r.Offset())
+	}
+}
+
+func TestResumeFromOffset(t *testing.T) {
+	data := snapshot(t, 20000)
+
+	r := NewReader[int, string](bytes.NewReader(data[:len(data)/2]))
+	actual := slicemultimap.New[int, string]()
+	if _, err := r.ReadInto(actual); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("expected %v, got %v", ErrTruncated, err)
+	}
+	if actual.Size() == 0 || actual.Size() == 20000 {
+		t.Errorf("expected a partially read snapshot, got %v entries", actual.Size())
+	}
+
+	r.Resume(bytes.NewReader(data[r.Offset():]))
+	if _, err := r.ReadInto(actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue := actual.Size(); actualValue != 20000 {
+		t.Errorf("expected %v, got %v", 20000, actualValue)
+	}
+}
+
+func TestCorrupt(t *testing.T) {
+	data := snapshot(t, 100)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{"empty", nil, ErrTruncated},
+		{"magic", append([]byte("XXXX"), data[4:]...), ErrFormat},
+		{"version", append(append([]byte(magic), Version+1), data[headerSize:]...), ErrFormat},
+		{"block type", withByte(data, headerSize, 9), ErrFormat},
+		{"block length", withByte(data, headerSize+4, 0xff), ErrFormat},
+		{"checksum", withByte(data, headerSize+5, data[headerSize+5]+1), ErrChecksum},
+		{"payload", withByte(data, headerSize+blockHeaderSize+1, data[headerSize+blockHeaderSize+1]+1), ErrChecksum},
+		{"missing end", data[:len(data)-blockHeaderSize-1], ErrTruncated},
+	}
+
+	for _, test := range tests {
+		actual := slicemultimap.New[int, string]()
+		r := NewReader[int, string](bytes.NewReader(test.data))
+		if _, err := r.ReadInto(actual); !errors.Is(err, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, err)
+		}
+		if test.expected == ErrTruncated {
+			continue
+		}
+		if _, err := r.ReadInto(actual); !errors.Is(err, test.expected) {
+			t.Errorf("%s: expected a permanent %v, got %v", test.name, test.expected, err)
+		}
+	}
+}
+
+func TestWriterError(t *testing.T) {
+	failure := errors.New("failure")
+	w := NewWriter[int, string](failingWriter{failure})
+	if err := w.Write(1, "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, failure) {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+	if err := w.Write(2, "b"); !errors.Is(err, failure) {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
+
+func FuzzReadInto(f *testing.F) {
+	m := slicemultimap.New[string, int]()
+	m.PutAll("a", []int{1, 2, 2})
+	m.Put("b", 3)
+	var buf bytes.Buffer
+	if err := Write[string, int](&buf, m); err != nil {
+		f.Fatalf("unexpected error: %v", err)
+	}
+	f.Add(buf.Bytes())
+	f.Add([]byte(magic))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		actual := slicemultimap.New[string, int]()
+		n, err := NewReader[string, int](bytes.NewReader(data)).ReadInto(actual)
+		if n != actual.Size() {
+			t.Fatalf("expected %v, got %v", actual.Size(), n)
+		}
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err := Write[string, int](&buf, actual); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := Read[string, int](&buf, slicemultimap.New[string, int]()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func BenchmarkWrite(b *testing.B) {
+	b.StopTimer()
+	m := slicemultimap.New[int, string]()
+	for i := 0; i < 100000; i++ {
+		m.Put(i%1000, fmt.Sprintf("value-%d", i))
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Write[int, string](io.Discard, m); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	b.StopTimer()
+	m := slicemultimap.New[int, string]()
+	for i := 0; i < 100000; i++ {
+		m.Put(i%1000, fmt.Sprintf("value-%d", i))
+	}
+	var buf bytes.Buffer
+	if err := Write[int, string](&buf, m); err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Read[int, string](bytes.NewReader(buf.Bytes()), slicemultimap.New[int, string]()); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+// snapshot returns a complete snapshot of n entries spread over several blocks.
+func snapshot(t *testing.T, n int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter[int, string](&buf)
+	for i := 0; i < n; i++ {
+		if err := w.Write(i%100, fmt.Sprintf("value-%d", i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if i%1000 == 999 {
+			if err := w.Flush(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func withByte(data []byte, i int, b byte) []byte {
+	data = bytes.Clone(data)
+	data[i] = b
+	return data
+}
+
+// growingReader reads data that may grow between reads, like a file being appended to.
+type growingReader struct {
+	data []byte
+	off  int
+}
+
+func (r *growingReader) Read(p []byte) (int, error) {
+	if r.off >= len(r.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[r.off:])
+	r.off += n
+	return n, nil
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func sameEntries[K comparable, V comparable](a, b []multimap.Entry[K, V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[multimap.Entry[K, V]]int)
+	for _, entry := range a {
+		counts[entry]++
+	}
+	for _, entry := range b {
+		if counts[entry] == 0 {
+			return false
+		}
+		counts[entry]--
+	}
+	return true
+}
+
+func sameOrder[V comparable](a, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
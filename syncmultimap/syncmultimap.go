@@ -0,0 +1,301 @@
+// Package syncmultimap implements a thread safe wrapper around any multimap.
+//
+// A syncmultimap guards every operation of the wrapped multimap with a read-write mutex,
+// so it can be shared between goroutines. Reads may proceed concurrently, writes are exclusive.
+//
+// Slices returned by Get, Entries, Keys, KeySet and Values are copies
+// that the caller may keep and modify without affecting the multimap.
+//
+// Besides the multimap.MultiMap methods, the wrapper offers compound operations
+// such as PutIfAbsent, ComputeIfAbsent and ReplaceValues that are executed atomically.
+//
+// The wrapped multimap must not be accessed directly once it is wrapped.
+package syncmultimap
+
+import (
+	"encoding"
+	"encoding/gob"
+	"iter"
+	"slices"
+	"sync"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+var (
+	_ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+	_ encoding.BinaryMarshaler    = &MultiMap[any, any]{}
+	_ encoding.BinaryUnmarshaler  = &MultiMap[any, any]{}
+	_ gob.GobEncoder              = &MultiMap[any, any]{}
+	_ gob.GobDecoder              = &MultiMap[any, any]{}
+)
+
+// MultiMap guards the wrapped multimap with a read-write mutex.
+type MultiMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  multimap.MultiMap[K, V]
+}
+
+// New instantiates a new thread safe multimap wrapping the multimap m.
+func New[K comparable, V any](m multimap.MultiMap[K, V]) *MultiMap[K, V] {
+	return &MultiMap[K, V]{m: m}
+}
+
+// Get searches the element in the multimap by key.
+// It returns a copy of its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values, found = m.m.Get(key)
+	return slices.Clone(values), found
+}
+
+// Put stores a key-value pair in this multimap.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m.Put(key, value)
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+// All of the values are stored atomically.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m.PutAll(key, values)
+}
+
+// PutIfAbsent stores a key-value pair in this multimap unless the multimap already contains it.
+// It returns true if the key-value pair was stored, otherwise false.
+func (m *MultiMap[K, V]) PutIfAbsent(key K, value V) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.m.Contains(key, value) {
+		return false
+	}
+	m.m.Put(key, value)
+	return true
+}
+
+// ComputeIfAbsent stores the values returned by compute for the key, unless the multimap already contains the key.
+// It returns a copy of the values associated with the key afterwards, or nil if there are none.
+// The compute function is called while the multimap is locked, so it must not access the multimap.
+func (m *MultiMap[K, V]) ComputeIfAbsent(key K, compute func(key K) []V) []V {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.m.ContainsKey(key) {
+		m.m.PutAll(key, compute(key))
+	}
+	values, _ := m.m.Get(key)
+	return slices.Clone(values)
+}
+
+// ReplaceValues atomically replaces all values associated with the key by the values.
+// It returns a copy of the values previously associated with the key, or nil if there were none.
+func (m *MultiMap[K, V]) ReplaceValues(key K, values []V) (previous []V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous, _ = m.m.Get(key)
+	previous = slices.Clone(previous)
+	m.m.RemoveAll(key)
+	m.m.PutAll(key, values)
+	return previous
+}
+
+// Contains returns true if this multimap contains at least one key-value pair with the key key and the value value.
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.m.Contains(key, value)
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.m.ContainsKey(key)
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.m.ContainsValue(value)
+}
+
+// Remove removes a single key-value pair from this multimap, if such exists.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m.Remove(key, value)
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m.RemoveAll(key)
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.m.Empty()
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.m.Size()
+}
+
+// Keys returns a copy of the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+func (m *MultiMap[K, V]) Keys() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.m.Keys())
+}
+
+// KeySet returns a copy of all distinct keys contained in this multimap.
+func (m *MultiMap[K, V]) KeySet() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.m.KeySet())
+}
+
+// Values returns a copy of all values from each key-value pair contained in this multimap.
+// This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
+func (m *MultiMap[K, V]) Values() []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.m.Values())
+}
+
+// Entries returns a copy of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances.
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.m.Entries())
+}
+
+// All returns an iterator over a snapshot of all key-value pairs contained in this multimap.
+// The snapshot is taken when the iteration starts, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, entry := range m.Entries() {
+			if !yield(entry.Key, entry.Value) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq returns an iterator over a snapshot of the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The snapshot is taken when the iteration starts, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, key := range m.Keys() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq returns an iterator over a snapshot of the value from each key-value pair in this multimap.
+// This is done without collapsing duplicates.
+// The snapshot is taken when the iteration starts, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range m.Values() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Sets returns an iterator over a snapshot of all distinct keys together with copies of their values.
+// The snapshot is taken when the iteration starts, so the multimap may be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		type set struct {
+			key    K
+			values []V
+		}
+		m.mu.RLock()
+		var sets []set
+		for key, values := range m.m.Sets() {
+			sets = append(sets, set{key: key, values: slices.Clone(values)})
+		}
+		m.mu.RUnlock()
+
+		for _, s := range sets {
+			if !yield(s.key, s.values) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m.Clear()
+}
+
+// Clone returns a new thread safe multimap wrapping a copy of the wrapped multimap.
+// The copy is created by newMultiMap, which has to return an empty multimap,
+// and is filled with all key-value pairs of the wrapped multimap atomically.
+// Pairs are put one by one in the order of All, so a copy into an insertion ordered multimap keeps the ordering.
+func (m *MultiMap[K, V]) Clone(newMultiMap func() multimap.MultiMap[K, V]) *MultiMap[K, V] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c := newMultiMap()
+	for key, value := range m.m.All() {
+		c.Put(key, value)
+	}
+	return New(c)
+}
+
+// MarshalBinary encodes the multimap into a compact binary form.
+// Every key-value pair is written in the order of All, so a wrapped insertion ordered multimap keeps its ordering.
+func (m *MultiMap[K, V]) MarshalBinary() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return codec.AppendEntries(nil, m.m.Size(), m.m.All())
+}
+
+// UnmarshalBinary decodes a multimap encoded by MarshalBinary, replacing all elements of the multimap.
+// Corrupt input yields an error and leaves the multimap unchanged.
+func (m *MultiMap[K, V]) UnmarshalBinary(data []byte) error {
+	entries, err := codec.DecodeEntries[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.m.Clear()
+	for _, entry := range entries {
+		m.m.Put(entry.Key, entry.Value)
+	}
+	return nil
+}
+
+// GobEncode encodes the multimap for encoding/gob using MarshalBinary.
+func (m *MultiMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode decodes the multimap for encoding/gob using UnmarshalBinary.
+func (m *MultiMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
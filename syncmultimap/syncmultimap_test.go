@@ -0,0 +1,489 @@
+package syncmultimap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/linkedmultimap"
+	"github.com/rafos/go-multimap/multimaptest"
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+)
+
+func TestPut(t *testing.T) {
+	m := New[int, string](slicemultimap.New[int, string]())
+	m.Put(5, "e")
+	m.Put(3, "c")
+	m.Put(1, "x")
+	m.Put(1, "a")
+	m.PutAll(2, []string{"b", "b"})
+
+	if actualValue := m.Size(); actualValue != 6 {
+		t.Errorf("expected %v, got %v", 6, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2, 2, 3, 5}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 5}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "b", "c", "e", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "x"},
+		{Key: 1, Value: "a"},
+		{Key: 2, Value: "b"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 5, Value: "e"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	if actualValue, expectedValue := m.Contains(1, "a"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsKey(4), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("e"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	m.Remove(1, "x")
+	m.RemoveAll(2)
+
+	if actualValue := m.Size(); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+	if actualValue, actualFound := m.Get(1); !sameElements(actualValue, []string{"a"}) || !actualFound {
+		t.Errorf("expected %v, got %v", []string{"a"}, actualValue)
+	}
+	if actualValue, actualFound := m.Get(2); actualValue != nil || actualFound {
+		t.Errorf("expected %v, got %v", nil, actualValue)
+	}
+
+	m.Clear()
+
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("expected %v, got %v", true, actualValue)
+	}
+}
+
+func TestDefensiveCopies(t *testing.T) {
+	m := New[int, string](slicemultimap.New[int, string]())
+	m.PutAll(1, []string{"a", "b", "c"})
+
+	values, _ := m.Get(1)
+	values[0] = "z"
+	_ = append(values[:1], "y")
+
+	entries := m.Entries()
+	entries[0].Value = "z"
+
+	all := m.Values()
+	all[0] = "z"
+
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Entries()), "[{1 a} {1 b} {1 c}]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	m := New[int, string](slicemultimap.New[int, string]())
+
+	if actualValue, expectedValue := m.PutIfAbsent(1, "a"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.PutIfAbsent(1, "a"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.PutIfAbsent(1, "b"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+}
+
+func TestComputeIfAbsent(t *testing.T) {
+	m := New[int, string](slicemultimap.New[int, string]())
+	m.Put(1, "a")
+
+	calls := 0
+	compute := func(key int) []string {
+		calls++
+		return []string{fmt.Sprint(key), "x"}
+	}
+
+	if actualValue, expectedValue := m.ComputeIfAbsent(1, compute), []string{"a"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ComputeIfAbsent(2, compute), []string{"2", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ComputeIfAbsent(2, compute), []string{"2", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue := m.ComputeIfAbsent(3, func(int) []string { return nil }); actualValue != nil {
+		t.Errorf("expected %v, got %v", nil, actualValue)
+	}
+	if calls != 1 {
+		t.Errorf("expected %v, got %v", 1, calls)
+	}
+	if actualValue := m.Size(); actualValue != 3 {
+		t.Errorf("expected %v, got %v", 3, actualValue)
+	}
+}
+
+func TestReplaceValues(t *testing.T) {
+	m := New[int, string](setmultimap.New[int, string]())
+	m.PutAll(1, []string{"a", "b"})
+
+	if actualValue, expectedValue := m.ReplaceValues(1, []string{"c", "d", "e"}), []string{"a", "b"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, _ := m.Get(1); !sameElements(actualValue, []string{"c", "d", "e"}) {
+		t.Errorf("expected %v, got %v", []string{"c", "d", "e"}, actualValue)
+	}
+	if actualValue := m.ReplaceValues(2, []string{"f"}); actualValue != nil {
+		t.Errorf("expected %v, got %v", nil, actualValue)
+	}
+	if actualValue := m.ReplaceValues(1, nil); !sameElements(actualValue, []string{"c", "d", "e"}) {
+		t.Errorf("expected %v, got %v", []string{"c", "d", "e"}, actualValue)
+	}
+	if actualValue := m.ContainsKey(1); actualValue != false {
+		t.Errorf("expected %v, got %v", false, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 1 {
+		t.Errorf("expected %v, got %v", 1, actualValue)
+	}
+}
+
+// The concurrency tests are meant to be run with the race detector: go test -race ./syncmultimap
+func TestConcurrentAccess(t *testing.T) {
+	m := New[int, int](slicemultimap.New[int, int]())
+	goroutines, operations := 8, 1000
+
+	var wg sync.WaitGroup
+	for g := 1; g <= goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				key := i % 10
+				m.Put(key, g)
+				if values, found := m.Get(key); found {
+					values[0] = -1
+				}
+				m.Contains(key, g)
+				m.ContainsValue(g)
+				m.Entries()
+				m.PutIfAbsent(key, -g)
+				m.ComputeIfAbsent(key+10, func(key int) []int { return []int{key} })
+				m.Remove(key, g)
+				m.Size()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for key := 0; key < 10; key++ {
+		values, _ := m.Get(key)
+		for _, value := range values {
+			if value > 0 {
+				t.Errorf("key %d: unexpected value %v left after removal", key, value)
+			}
+		}
+	}
+	for key := 10; key < 20; key++ {
+		if values, _ := m.Get(key); !sameElements(values, []int{key}) {
+			t.Errorf("expected %v, got %v", []int{key}, values)
+		}
+	}
+}
+
+func TestConcurrentCompoundOperations(t *testing.T) {
+	m := New[int, int](slicemultimap.New[int, int]())
+	goroutines, keys := 8, 100
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := 0; key < keys; key++ {
+				m.PutIfAbsent(key, key)
+				m.ReplaceValues(-key-1, []int{key, key})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if actualValue, expectedValue := m.Size(), 3*keys; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return New[string, string](slicemultimap.New[string, string]())
+	}, multimaptest.List)
+}
+
+func TestIterators(t *testing.T) {
+	m := New[int, string](slicemultimap.New[int, string]())
+	m.Put(5, "e")
+	m.Put(3, "c")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+	m.PutAll(4, []string{"d", "y"})
+
+	var entries []multimap.Entry[int, string]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[int, string]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keys []int
+	for key := range m.KeysSeq() {
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := keys, m.Keys(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var values []string
+	for value := range m.ValuesSeq() {
+		values = append(values, value)
+	}
+	if actualValue, expectedValue := values, m.Values(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keySet []int
+	for key, values := range m.Sets() {
+		keySet = append(keySet, key)
+		if expectedValue, _ := m.Get(key); !sameElements(values, expectedValue) {
+			t.Errorf("key %d: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+	if actualValue, expectedValue := keySet, m.KeySet(); !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	count := 0
+	for range m.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	for range m.Sets() {
+		count++
+		break
+	}
+	if count != 4 {
+		t.Errorf("expected %v, got %v", 4, count)
+	}
+
+	m.Clear()
+
+	for key, value := range m.All() {
+		t.Errorf("unexpected entry %v: %v", key, value)
+	}
+}
+
+func TestIterateWhileModifying(t *testing.T) {
+	m := New[int, string](slicemultimap.New[int, string]())
+	m.PutAll(1, []string{"a", "b"})
+	m.Put(2, "c")
+
+	for key, value := range m.All() {
+		m.Put(key+10, value)
+	}
+	for key := range m.Sets() {
+		m.RemoveAll(key)
+	}
+
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := New[string, int](slicemultimap.New[string, int]())
+	m.PutAll("a", []int{3, 1, 2})
+	m.Put("b", 4)
+
+	c := m.Clone(func() multimap.MultiMap[string, int] {
+		return slicemultimap.New[string, int]()
+	})
+	if !multimap.Equal[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+	if actualValue, expectedValue := multimap.Hash[string, int](c), multimap.Hash[string, int](m); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	c.Put("a", 5)
+	c.Remove("b", 4)
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.Contains("b", 4) || m.Contains("a", 5) {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+
+	m.RemoveAll("a")
+	if actualValue, _ := c.Get("a"); !sameElements(actualValue, []int{3, 1, 2, 5}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 5}, actualValue)
+	}
+}
+
+// foreign stands for a multimap of another module, which has no Clone method.
+type foreign struct {
+	multimap.MultiMap[string, int]
+}
+
+func TestCloneForeign(t *testing.T) {
+	m := New[string, int](foreign{slicemultimap.New[string, int]()})
+	m.PutAll("a", []int{1, 2})
+
+	c := m.Clone(func() multimap.MultiMap[string, int] {
+		return foreign{slicemultimap.New[string, int]()}
+	})
+	m.Put("b", 3)
+	if actualValue, _ := c.Get("a"); !sameElements(actualValue, []int{1, 2}) {
+		t.Errorf("expected %v, got %v", []int{1, 2}, actualValue)
+	}
+	if actualValue := c.Size(); actualValue != 2 {
+		t.Errorf("expected %v, got %v", 2, actualValue)
+	}
+
+	// Pairs are copied one by one, so an insertion ordered multimap keeps interleaved keys in order.
+	linked := New[string, int](foreign{linkedmultimap.New[string, int]()})
+	linked.Put("a", 1)
+	linked.Put("b", 2)
+	linked.Put("a", 3)
+	c = linked.Clone(func() multimap.MultiMap[string, int] {
+		return foreign{linkedmultimap.New[string, int]()}
+	})
+	expected := []multimap.Entry[string, int]{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "a", Value: 3}}
+	if actualValue := c.Entries(); !slices.Equal(actualValue, expected) {
+		t.Errorf("expected %v, got %v", expected, actualValue)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	m := New[int, string](slicemultimap.New[int, string]())
+	m.PutAll(2, []string{"b", "a"})
+	m.Put(1, "x")
+	m.Put(3, "")
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := New[int, string](slicemultimap.New[int, string]())
+	actual.Put(4, "d")
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual = New[int, string](slicemultimap.New[int, string]())
+	if err := gob.NewDecoder(&buf).Decode(actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if err := actual.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated at %d: expected an error", i)
+		}
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestMarshalBinaryInsertionOrder(t *testing.T) {
+	m := New[string, int](linkedmultimap.New[string, int]())
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("a", 3)
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := New[string, int](linkedmultimap.New[string, int]())
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []multimap.Entry[string, int]{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "a", Value: 3}}
+	if actualValue := actual.Entries(); !slices.Equal(actualValue, expected) {
+		t.Errorf("expected %v, got %v", expected, actualValue)
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av == bv {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check equality of entries.
+func sameEntries[K comparable, V comparable](a []multimap.Entry[K, V], b []multimap.Entry[K, V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av.Key == bv.Key && av.Value == bv.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package multimap
+
+import "iter"
+
+// Collect returns a new multimap, created by newMultiMap, holding the key-value pairs of seq.
+// It materializes the lazy sequences returned by FilterEntriesSeq, TransformValuesSeq and the like.
+func Collect[K comparable, V any, M MultiMap[K, V]](seq iter.Seq2[K, V], newMultiMap func() M) M {
+	dst := newMultiMap()
+	for key, value := range seq {
+		dst.Put(key, value)
+	}
+	return dst
+}
+
+// FilterEntries returns a new multimap, created by newMultiMap, holding the key-value pairs of src
+// for which keep returns true.
+func FilterEntries[K comparable, V any, M MultiMap[K, V]](src MultiMap[K, V], keep func(K, V) bool, newMultiMap func() M) M {
+	return Collect(FilterEntriesSeq(src, keep), newMultiMap)
+}
+
+// FilterKeys returns a new multimap, created by newMultiMap, holding the key-value pairs of src
+// whose key keep returns true for.
+func FilterKeys[K comparable, V any, M MultiMap[K, V]](src MultiMap[K, V], keep func(K) bool, newMultiMap func() M) M {
+	dst := newMultiMap()
+	for key, values := range src.Sets() {
+		if keep(key) {
+			dst.PutAll(key, values)
+		}
+	}
+	return dst
+}
+
+// FilterValues returns a new multimap, created by newMultiMap, holding the key-value pairs of src
+// whose value keep returns true for.
+func FilterValues[K comparable, V any, M MultiMap[K, V]](src MultiMap[K, V], keep func(V) bool, newMultiMap func() M) M {
+	return Collect(FilterValuesSeq(src, keep), newMultiMap)
+}
+
+// TransformValues returns a new multimap, created by newMultiMap, holding a key-value pair for every
+// key-value pair of src, with the value replaced by the result of transform.
+// Different values transformed to the same result are collapsed if the returned multimap rejects duplicates.
+func TransformValues[K comparable, V any, W any, M MultiMap[K, W]](src MultiMap[K, V], transform func(V) W, newMultiMap func() M) M {
+	return Collect(TransformValuesSeq(src, transform), newMultiMap)
+}
+
+// TransformKeys returns a new multimap, created by newMultiMap, holding a key-value pair for every
+// key-value pair of src, with the key replaced by the result of transform.
+// The values of different keys transformed to the same result are merged into the values of that result.
+func TransformKeys[K comparable, J comparable, V any, M MultiMap[J, V]](src MultiMap[K, V], transform func(K) J, newMultiMap func() M) M {
+	dst := newMultiMap()
+	for key, values := range src.Sets() {
+		dst.PutAll(transform(key), values)
+	}
+	return dst
+}
+
+// FilterEntriesSeq returns a lazy view of the key-value pairs of src for which keep returns true.
+// No multimap is built: src is read each time the sequence is iterated, so the view reflects later
+// modifications of src. Like with src.All, src must not be modified during the iteration.
+func FilterEntriesSeq[K comparable, V any](src MultiMap[K, V], keep func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, value := range src.All() {
+			if keep(key, value) && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// FilterKeysSeq returns a lazy view of the key-value pairs of src whose key keep returns true for.
+// keep is called once for every distinct key. Like FilterEntriesSeq, no multimap is built.
+func FilterKeysSeq[K comparable, V any](src MultiMap[K, V], keep func(K) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, values := range src.Sets() {
+			if !keep(key) {
+				continue
+			}
+			for _, value := range values {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FilterValuesSeq returns a lazy view of the key-value pairs of src whose value keep returns true for.
+// Like FilterEntriesSeq, no multimap is built.
+func FilterValuesSeq[K comparable, V any](src MultiMap[K, V], keep func(V) bool) iter.Seq2[K, V] {
+	return FilterEntriesSeq(src, func(_ K, value V) bool { return keep(value) })
+}
+
+// TransformValuesSeq returns a lazy view of the key-value pairs of src with every value replaced
+// by the result of transform. transform is called each time a pair is yielded.
+// Like FilterEntriesSeq, no multimap is built.
+func TransformValuesSeq[K comparable, V any, W any](src MultiMap[K, V], transform func(V) W) iter.Seq2[K, W] {
+	return func(yield func(K, W) bool) {
+		for key, value := range src.All() {
+			if !yield(key, transform(value)) {
+				return
+			}
+		}
+	}
+}
+
+// TransformKeysSeq returns a lazy view of the key-value pairs of src with every key replaced
+// by the result of transform. transform is called once for every distinct key.
+// Pairs of keys transformed to the same result are not merged, they are yielded as they are met.
+// Like FilterEntriesSeq, no multimap is built.
+func TransformKeysSeq[K comparable, J comparable, V any](src MultiMap[K, V], transform func(K) J) iter.Seq2[J, V] {
+	return func(yield func(J, V) bool) {
+		for key, values := range src.Sets() {
+			transformed := transform(key)
+			for _, value := range values {
+				if !yield(transformed, value) {
+					return
+				}
+			}
+		}
+	}
+}
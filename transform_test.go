@@ -0,0 +1,176 @@
+package multimap_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/eqmultimap"
+	"github.com/rafos/go-multimap/linkedmultimap"
+	"github.com/rafos/go-multimap/setmultimap"
+	"github.com/rafos/go-multimap/slicemultimap"
+)
+
+// source returns a multimap of users to the ids of their documents.
+func source() *slicemultimap.MultiMap[string, int] {
+	m := slicemultimap.New[string, int]()
+	m.PutAll("alice", []int{1, 2, 3, 2})
+	m.PutAll("bob", []int{4})
+	m.PutAll("Carol", []int{5, 6})
+	return m
+}
+
+func TestFilter(t *testing.T) {
+	m := source()
+
+	even := func(value int) bool { return value%2 == 0 }
+	lower := func(key string) bool { return key == strings.ToLower(key) }
+
+	tests := []struct {
+		name     string
+		actual   *slicemultimap.MultiMap[string, int]
+		expected map[string][]int
+	}{
+		{"entries", multimap.FilterEntries(m, func(key string, value int) bool { return key == "alice" && value > 1 }, slicemultimap.New[string, int]),
+			map[string][]int{"alice": {2, 3, 2}}},
+		{"keys", multimap.FilterKeys(m, lower, slicemultimap.New[string, int]),
+			map[string][]int{"alice": {1, 2, 3, 2}, "bob": {4}}},
+		{"values", multimap.FilterValues(m, even, slicemultimap.New[string, int]),
+			map[string][]int{"alice": {2, 2}, "bob": {4}, "Carol": {6}}},
+		{"none", multimap.FilterValues(m, func(int) bool { return false }, slicemultimap.New[string, int]),
+			map[string][]int{}},
+	}
+
+	for _, test := range tests {
+		expected := slicemultimap.New[string, int]()
+		for key, values := range test.expected {
+			expected.PutAll(key, values)
+		}
+		if !multimap.Equal[string, int](test.actual, expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, expected.Entries(), test.actual.Entries())
+		}
+	}
+
+	if actualValue := m.Size(); actualValue != 7 {
+		t.Errorf("expected %v, got %v", 7, actualValue)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	m := source()
+
+	names := multimap.TransformValues(m, func(id int) string { return "doc-" + strconv.Itoa(id) }, slicemultimap.New[string, string])
+	if actualValue, _ := names.Get("alice"); !sameOrder(actualValue, []string{"doc-1", "doc-2", "doc-3", "doc-2"}) {
+		t.Errorf("expected %v, got %v", []string{"doc-1", "doc-2", "doc-3", "doc-2"}, actualValue)
+	}
+	if actualValue := names.Size(); actualValue != 7 {
+		t.Errorf("expected %v, got %v", 7, actualValue)
+	}
+
+	parity := multimap.TransformValues(m, func(id int) bool { return id%2 == 0 }, setmultimap.New[string, bool])
+	if actualValue, _ := parity.Get("alice"); !sameElements(actualValue, []bool{true, false}) {
+		t.Errorf("expected %v, got %v", []bool{true, false}, actualValue)
+	}
+
+	initials := multimap.TransformKeys(m, func(key string) byte { return strings.ToLower(key)[0] }, slicemultimap.New[byte, int])
+	if actualValue, _ := initials.Get('c'); !sameOrder(actualValue, []int{5, 6}) {
+		t.Errorf("expected %v, got %v", []int{5, 6}, actualValue)
+	}
+
+	digits := multimap.TransformValues(m, func(id int) []byte { return []byte(strconv.Itoa(id)) }, func() *eqmultimap.MultiMap[string, []byte] {
+		return eqmultimap.New[string](bytes.Equal)
+	})
+	if !digits.Contains("Carol", []byte("6")) || digits.Contains("bob", []byte("6")) {
+		t.Errorf("expected values to be compared with bytes.Equal, got %v", digits.Entries())
+	}
+
+	merged := multimap.TransformKeys(m, func(string) int { return 0 }, slicemultimap.New[int, int])
+	if actualValue, _ := merged.Get(0); !sameElements(actualValue, m.Values()) || len(actualValue) != m.Size() {
+		t.Errorf("expected %v, got %v", m.Values(), actualValue)
+	}
+}
+
+func TestLazyViews(t *testing.T) {
+	m := linkedmultimap.New[string, int]()
+	m.PutAll("a", []int{1, 2})
+	m.Put("b", 3)
+	m.Put("a", 4)
+
+	calls := 0
+	odd := multimap.FilterValuesSeq[string, int](m, func(value int) bool {
+		calls++
+		return value%2 == 1
+	})
+	if calls != 0 {
+		t.Errorf("expected %v, got %v", 0, calls)
+	}
+
+	var entries []multimap.Entry[string, int]
+	for key, value := range odd {
+		entries = append(entries, multimap.Entry[string, int]{Key: key, Value: value})
+	}
+	if expected := []multimap.Entry[string, int]{{Key: "a", Value: 1}, {Key: "b", Value: 3}}; !sameOrder(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+
+	m.Put("c", 5)
+	if actual := multimap.Collect(odd, slicemultimap.New[string, int]); !actual.Contains("c", 5) || actual.Size() != 3 {
+		t.Errorf("expected the view to reflect later modifications, got %v", actual.Entries())
+	}
+
+	count := 0
+	for range multimap.TransformValuesSeq[string, int](m, strconv.Itoa) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("expected %v, got %v", 2, count)
+	}
+
+	var keys []string
+	for key := range multimap.FilterKeysSeq[string, int](m, func(key string) bool { return key != "b" }) {
+		keys = append(keys, key)
+	}
+	if expected := []string{"a", "a", "a", "c"}; !sameOrder(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+
+	var upper []multimap.Entry[string, int]
+	for key, value := range multimap.TransformKeysSeq[string, string, int](m, strings.ToUpper) {
+		upper = append(upper, multimap.Entry[string, int]{Key: key, Value: value})
+	}
+	expected := []multimap.Entry[string, int]{{Key: "A", Value: 1}, {Key: "A", Value: 2}, {Key: "A", Value: 4}, {Key: "B", Value: 3}, {Key: "C", Value: 5}}
+	if !sameOrder(upper, expected) {
+		t.Errorf("expected %v, got %v", expected, upper)
+	}
+
+	var filtered []multimap.Entry[string, int]
+	for key, value := range multimap.FilterEntriesSeq[string, int](m, func(key string, value int) bool { return key == "a" && value > 1 }) {
+		filtered = append(filtered, multimap.Entry[string, int]{Key: key, Value: value})
+	}
+	if expected := []multimap.Entry[string, int]{{Key: "a", Value: 2}, {Key: "a", Value: 4}}; !sameOrder(filtered, expected) {
+		t.Errorf("expected %v, got %v", expected, filtered)
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[V]int)
+	for _, value := range a {
+		counts[value]++
+	}
+	for _, value := range b {
+		if counts[value] == 0 {
+			return false
+		}
+		counts[value]--
+	}
+	return true
+}
@@ -0,0 +1,554 @@
+// Package treemultimap implements a multimap backed by a balanced binary search tree.
+//
+// A treemultimap is a multimap that can hold duplicate key-value pairs
+// and that maintains the insertion ordering of values for a given key.
+//
+// Keys are kept sorted by a comparison function supplied on creation,
+// so KeySet, Keys, Values and Entries are returned in ascending key order.
+// Besides the multimap.MultiMap methods, the multimap can be navigated
+// with Floor, Ceiling, Range, Head and Tail.
+//
+// This multimap is typically known as TreeMultimap in other languages,
+// with the difference that values of a given key are not sorted.
+//
+// Structure is not thread safe.
+package treemultimap
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/gob"
+	"errors"
+	"iter"
+	"slices"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/internal/codec"
+)
+
+var (
+	_ multimap.MultiMap[any, any] = &MultiMap[any, any]{}
+	_ encoding.BinaryMarshaler    = &MultiMap[any, any]{}
+	_ encoding.BinaryUnmarshaler  = &MultiMap[any, any]{}
+	_ gob.GobEncoder              = &MultiMap[any, any]{}
+	_ gob.GobDecoder              = &MultiMap[any, any]{}
+)
+
+// node holds a key with all its values inside an AVL tree.
+type node[K comparable, V comparable] struct {
+	key         K
+	values      []V
+	left, right *node[K, V]
+	height      int
+}
+
+// MultiMap holds the elements in an AVL tree ordered by key.
+type MultiMap[K comparable, V comparable] struct {
+	root    *node[K, V]
+	compare func(a, b K) int
+	keys    int
+	size    int
+}
+
+// New instantiates a new multimap ordering its keys with the compare function.
+// The compare function returns a negative number when a < b, a positive number when a > b and zero when a == b.
+func New[K comparable, V comparable](compare func(a, b K) int) *MultiMap[K, V] {
+	return &MultiMap[K, V]{compare: compare}
+}
+
+// NewOrdered instantiates a new multimap ordering its keys with cmp.Compare.
+func NewOrdered[K cmp.Ordered, V comparable]() *MultiMap[K, V] {
+	return New[K, V](cmp.Compare[K])
+}
+
+// Get searches the element in the multimap by key.
+// It returns a copy of its values or nil if key is not found in multimap.
+// Second return parameter is true if key was found, otherwise false.
+func (m *MultiMap[K, V]) Get(key K) (values []V, found bool) {
+	if n := m.lookup(key); n != nil {
+		return slices.Clone(n.values), true
+	}
+	return nil, false
+}
+
+// Put stores a key-value pair in this multimap.
+func (m *MultiMap[K, V]) Put(key K, value V) {
+	if n := m.lookup(key); n != nil {
+		n.values = append(n.values, value)
+	} else {
+		m.root = m.insert(m.root, key, value)
+		m.keys++
+	}
+	m.size++
+}
+
+// PutAll stores a key-value pair in this multimap for each of the values, all using the same key key.
+func (m *MultiMap[K, V]) PutAll(key K, values []V) {
+	for _, value := range values {
+		m.Put(key, value)
+	}
+}
+
+// Contains returns true if this multimap contains at least one key-value pair with the key key and the value value.
+func (m *MultiMap[K, V]) Contains(key K, value V) bool {
+	if n := m.lookup(key); n != nil {
+		for _, v := range n.values {
+			if v == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ContainsKey returns true if this multimap contains at least one key-value pair with the key key.
+func (m *MultiMap[K, V]) ContainsKey(key K) bool {
+	return m.lookup(key) != nil
+}
+
+// ContainsValue returns true if this multimap contains at least one key-value pair with the value value.
+func (m *MultiMap[K, V]) ContainsValue(value V) bool {
+	found := false
+	m.ascend(m.root, func(n *node[K, V]) bool {
+		for _, v := range n.values {
+			if v == value {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// Remove removes a single key-value pair from this multimap, if such exists.
+// When the key holds the value several times, its first occurrence is removed.
+func (m *MultiMap[K, V]) Remove(key K, value V) {
+	n := m.lookup(key)
+	if n == nil {
+		return
+	}
+	for i, v := range n.values {
+		if v == value {
+			n.values = append(n.values[:i], n.values[i+1:]...)
+			m.size--
+			break
+		}
+	}
+	if len(n.values) == 0 {
+		m.root = m.delete(m.root, key)
+		m.keys--
+	}
+}
+
+// RemoveAll removes all values associated with the key from the multimap.
+func (m *MultiMap[K, V]) RemoveAll(key K) {
+	n := m.lookup(key)
+	if n == nil {
+		return
+	}
+	m.size -= len(n.values)
+	m.root = m.delete(m.root, key)
+	m.keys--
+}
+
+// Empty returns true if multimap does not contain any key-value pairs.
+func (m *MultiMap[K, V]) Empty() bool {
+	return m.size == 0
+}
+
+// Size returns number of key-value pairs in the multimap.
+func (m *MultiMap[K, V]) Size() int {
+	return m.size
+}
+
+// Keys returns a view collection containing the key from each key-value pair in this multimap.
+// This is done without collapsing duplicates. Keys are returned in ascending order.
+func (m *MultiMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.size)
+	m.ascend(m.root, func(n *node[K, V]) bool {
+		for range n.values {
+			keys = append(keys, n.key)
+		}
+		return true
+	})
+	return keys
+}
+
+// KeySet returns all distinct keys contained in this multimap in ascending order.
+func (m *MultiMap[K, V]) KeySet() []K {
+	keys := make([]K, 0, m.keys)
+	m.ascend(m.root, func(n *node[K, V]) bool {
+		keys = append(keys, n.key)
+		return true
+	})
+	return keys
+}
+
+// Values returns all values from each key-value pair contained in this multimap.
+// This is done without collapsing duplicates. (size of Values() = MultiMap.Size()).
+// Values are returned in ascending order of their keys.
+func (m *MultiMap[K, V]) Values() []V {
+	values := make([]V, 0, m.size)
+	m.ascend(m.root, func(n *node[K, V]) bool {
+		values = append(values, n.values...)
+		return true
+	})
+	return values
+}
+
+// Entries view collection of all key-value pairs contained in this multimap.
+// The return type is a slice of multimap.Entry instances in ascending order of their keys.
+// Retrieving the key and value from the entries result will be as trivial as:
+//   - var entry = m.Entries()[0]
+//   - var key = entry.Key
+//   - var value = entry.Value
+func (m *MultiMap[K, V]) Entries() []multimap.Entry[K, V] {
+	entries := make([]multimap.Entry[K, V], 0, m.size)
+	m.ascend(m.root, func(n *node[K, V]) bool {
+		entries = appendEntries(entries, n)
+		return true
+	})
+	return entries
+}
+
+// All returns an iterator over all key-value pairs contained in this multimap in ascending order of their keys.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.ascend(m.root, func(n *node[K, V]) bool {
+			for _, value := range n.values {
+				if !yield(n.key, value) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// KeysSeq returns an iterator over the key from each key-value pair in this multimap in ascending order.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.ascend(m.root, func(n *node[K, V]) bool {
+			for range n.values {
+				if !yield(n.key) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// ValuesSeq returns an iterator over the value from each key-value pair in this multimap in ascending order of their keys.
+// This is done without collapsing duplicates.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.ascend(m.root, func(n *node[K, V]) bool {
+			for _, value := range n.values {
+				if !yield(value) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// Sets returns an iterator over all distinct keys in ascending order together with their values.
+// The yielded values are not copied and must not be modified.
+// The multimap must not be modified during the iteration.
+func (m *MultiMap[K, V]) Sets() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		m.ascend(m.root, func(n *node[K, V]) bool {
+			return yield(n.key, n.values[:len(n.values):len(n.values)])
+		})
+	}
+}
+
+// Clear removes all elements from the map.
+func (m *MultiMap[K, V]) Clear() {
+	m.root = nil
+	m.keys = 0
+	m.size = 0
+}
+
+// Clone returns a deep copy of the multimap using the same comparison function.
+// The values of every key are copied, so the clone and the multimap can be modified independently.
+func (m *MultiMap[K, V]) Clone() *MultiMap[K, V] {
+	return &MultiMap[K, V]{root: clone(m.root), compare: m.compare, keys: m.keys, size: m.size}
+}
+
+// clone copies the subtree rooted at n, keeping its shape.
+func clone[K comparable, V comparable](n *node[K, V]) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	return &node[K, V]{
+		key:    n.key,
+		values: slices.Clone(n.values),
+		left:   clone(n.left),
+		right:  clone(n.right),
+		height: n.height,
+	}
+}
+
+// Floor returns the greatest key less than or equal to the given key together with a copy of its values.
+// Third return parameter is true if such key was found, otherwise false.
+func (m *MultiMap[K, V]) Floor(key K) (floorKey K, values []V, found bool) {
+	var floor *node[K, V]
+	for n := m.root; n != nil; {
+		c := m.compare(key, n.key)
+		switch {
+		case c == 0:
+			return n.key, slices.Clone(n.values), true
+		case c < 0:
+			n = n.left
+		default:
+			floor = n
+			n = n.right
+		}
+	}
+	if floor == nil {
+		return floorKey, nil, false
+	}
+	return floor.key, slices.Clone(floor.values), true
+}
+
+// Ceiling returns the least key greater than or equal to the given key together with a copy of its values.
+// Third return parameter is true if such key was found, otherwise false.
+func (m *MultiMap[K, V]) Ceiling(key K) (ceilingKey K, values []V, found bool) {
+	var ceiling *node[K, V]
+	for n := m.root; n != nil; {
+		c := m.compare(key, n.key)
+		switch {
+		case c == 0:
+			return n.key, slices.Clone(n.values), true
+		case c > 0:
+			n = n.right
+		default:
+			ceiling = n
+			n = n.left
+		}
+	}
+	if ceiling == nil {
+		return ceilingKey, nil, false
+	}
+	return ceiling.key, slices.Clone(ceiling.values), true
+}
+
+// Range returns all key-value pairs whose keys are greater than or equal to from and strictly less than to.
+// Entries are returned in ascending order of their keys.
+func (m *MultiMap[K, V]) Range(from, to K) []multimap.Entry[K, V] {
+	var entries []multimap.Entry[K, V]
+	m.ascendRange(m.root, &from, &to, func(n *node[K, V]) {
+		entries = appendEntries(entries, n)
+	})
+	return entries
+}
+
+// Head returns all key-value pairs whose keys are strictly less than to.
+// Entries are returned in ascending order of their keys.
+func (m *MultiMap[K, V]) Head(to K) []multimap.Entry[K, V] {
+	var entries []multimap.Entry[K, V]
+	m.ascendRange(m.root, nil, &to, func(n *node[K, V]) {
+		entries = appendEntries(entries, n)
+	})
+	return entries
+}
+
+// Tail returns all key-value pairs whose keys are greater than or equal to from.
+// Entries are returned in ascending order of their keys.
+func (m *MultiMap[K, V]) Tail(from K) []multimap.Entry[K, V] {
+	var entries []multimap.Entry[K, V]
+	m.ascendRange(m.root, &from, nil, func(n *node[K, V]) {
+		entries = appendEntries(entries, n)
+	})
+	return entries
+}
+
+func appendEntries[K comparable, V comparable](entries []multimap.Entry[K, V], n *node[K, V]) []multimap.Entry[K, V] {
+	for _, value := range n.values {
+		entries = append(entries, multimap.Entry[K, V]{Key: n.key, Value: value})
+	}
+	return entries
+}
+
+// lookup returns the node holding the key or nil if key is not found.
+func (m *MultiMap[K, V]) lookup(key K) *node[K, V] {
+	for n := m.root; n != nil; {
+		c := m.compare(key, n.key)
+		switch {
+		case c == 0:
+			return n
+		case c < 0:
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return nil
+}
+
+// ascend calls f for every node of the subtree in ascending key order until f returns false.
+func (m *MultiMap[K, V]) ascend(n *node[K, V], f func(n *node[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	return m.ascend(n.left, f) && f(n) && m.ascend(n.right, f)
+}
+
+// ascendRange calls f for every node of the subtree with from <= key < to in ascending key order.
+// A nil bound leaves that side of the range open.
+func (m *MultiMap[K, V]) ascendRange(n *node[K, V], from, to *K, f func(n *node[K, V])) {
+	if n == nil {
+		return
+	}
+	aboveFrom := from == nil || m.compare(n.key, *from) >= 0
+	belowTo := to == nil || m.compare(n.key, *to) < 0
+	if aboveFrom {
+		m.ascendRange(n.left, from, to, f)
+	}
+	if aboveFrom && belowTo {
+		f(n)
+	}
+	if belowTo {
+		m.ascendRange(n.right, from, to, f)
+	}
+}
+
+// insert adds a new node for the key to the subtree and returns the rebalanced subtree.
+// The key must not be present in the subtree.
+func (m *MultiMap[K, V]) insert(n *node[K, V], key K, value V) *node[K, V] {
+	if n == nil {
+		return &node[K, V]{key: key, values: []V{value}, height: 1}
+	}
+	if m.compare(key, n.key) < 0 {
+		n.left = m.insert(n.left, key, value)
+	} else {
+		n.right = m.insert(n.right, key, value)
+	}
+	return rebalance(n)
+}
+
+// delete removes the node of the key from the subtree and returns the rebalanced subtree.
+func (m *MultiMap[K, V]) delete(n *node[K, V], key K) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	c := m.compare(key, n.key)
+	switch {
+	case c < 0:
+		n.left = m.delete(n.left, key)
+	case c > 0:
+		n.right = m.delete(n.right, key)
+	default:
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		successor.right = deleteMin(n.right)
+		successor.left = n.left
+		n = successor
+	}
+	return rebalance(n)
+}
+
+// deleteMin removes the leftmost node from the subtree and returns the rebalanced subtree.
+func deleteMin[K comparable, V comparable](n *node[K, V]) *node[K, V] {
+	if n.left == nil {
+		return n.right
+	}
+	n.left = deleteMin(n.left)
+	return rebalance(n)
+}
+
+func height[K comparable, V comparable](n *node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *node[K, V]) update() {
+	n.height = max(height(n.left), height(n.right)) + 1
+}
+
+func rotateLeft[K comparable, V comparable](n *node[K, V]) *node[K, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+func rotateRight[K comparable, V comparable](n *node[K, V]) *node[K, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+// rebalance restores the AVL property of the node and returns the new root of its subtree.
+func rebalance[K comparable, V comparable](n *node[K, V]) *node[K, V] {
+	n.update()
+	switch balance := height(n.left) - height(n.right); {
+	case balance > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+// MarshalBinary encodes the multimap into a compact binary form.
+// Every key is written once, followed by the number of its values and the values themselves.
+func (m *MultiMap[K, V]) MarshalBinary() ([]byte, error) {
+	return codec.AppendMultiMap(nil, m.keys, m.Sets())
+}
+
+// UnmarshalBinary decodes a multimap encoded by MarshalBinary, replacing all elements of the multimap.
+// Corrupt input yields an error and leaves the multimap unchanged.
+func (m *MultiMap[K, V]) UnmarshalBinary(data []byte) error {
+	if m.compare == nil {
+		return errors.New("treemultimap: multimap has no comparison function, create it with New or NewOrdered")
+	}
+	sets, err := codec.DecodeMultiMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.Clear()
+	for _, set := range sets {
+		m.PutAll(set.Key, set.Values)
+	}
+	return nil
+}
+
+// GobEncode encodes the multimap for encoding/gob using MarshalBinary.
+func (m *MultiMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode decodes the multimap for encoding/gob using UnmarshalBinary.
+func (m *MultiMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
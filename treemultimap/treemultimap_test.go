@@ -0,0 +1,982 @@
+package treemultimap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/rafos/go-multimap"
+	"github.com/rafos/go-multimap/multimaptest"
+)
+
+func TestClear(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	if actualValue := m.Size(); actualValue != 8 {
+		t.Errorf("expected %v, got %v", 8, actualValue)
+	}
+	if actualEmpty := m.Empty(); actualEmpty != false {
+		t.Errorf("expected an empty multimap: %v, got %v", false, actualEmpty)
+	}
+
+	m.Clear()
+
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+	if actualEmpty := m.Empty(); actualEmpty != true {
+		t.Errorf("expected an empty multimap: %v, got %v", true, actualEmpty)
+	}
+}
+func TestPut(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	if actualValue := m.Size(); actualValue != 8 {
+		t.Errorf("expected %v, got %v", 8, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2, 3, 4, 5, 6, 7}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 4, 5, 6, 7}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "c", "d", "e", "f", "g", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "a"},
+		{Key: 1, Value: "x"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 4, Value: "d"},
+		{Key: 5, Value: "e"},
+		{Key: 6, Value: "f"},
+		{Key: 7, Value: "g"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, []string{"a", "x"}, true},
+		{2, []string{"b"}, true},
+		{3, []string{"c"}, true},
+		{4, []string{"d"}, true},
+		{5, []string{"e"}, true},
+		{6, []string{"f"}, true},
+		{7, []string{"g"}, true},
+		{8, nil, false},
+		{9, nil, false},
+	}
+
+	for i, test := range tests {
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+}
+
+func TestPutAll(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(2, "b")
+	m.PutAll(1, []string{"a", "x", "y"})
+
+	if actualValue := m.Size(); actualValue != 6 {
+		t.Errorf("expected %v, got %v", 6, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "c", "d", "x", "y"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "a"},
+		{Key: 1, Value: "x"},
+		{Key: 1, Value: "y"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 4, Value: "d"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, []string{"a", "x", "y"}, true},
+		{2, []string{"b"}, true},
+		{3, []string{"c"}, true},
+		{4, []string{"d"}, true},
+		{5, nil, false},
+		{6, nil, false},
+	}
+
+	for i, test := range tests {
+		// Test for retrievals.
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(2, "b")
+	m.PutAll(1, []string{"a", "x", "y"})
+
+	if actualValue, expectedValue := m.Contains(1, "a"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Contains(1, "x"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Contains(1, "z"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsKey(1), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsKey(5), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("x"), true; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.ContainsValue("z"), false; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+func TestRemove(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	m.Remove(5, "n")
+	m.Remove(6, "f")
+	m.Remove(7, "g")
+	m.Remove(8, "h")
+	m.Remove(5, "e")
+
+	if actualValue := m.Size(); actualValue != 5 {
+		t.Errorf("expected %v, got %v", 5, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 4}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"a", "b", "c", "d", "x"}; !sameElements(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "a"},
+		{Key: 1, Value: "x"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 4, Value: "d"},
+	}
+	if actualValue := m.Entries(); !sameEntries(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, []string{"a", "x"}, true},
+		{2, []string{"b"}, true},
+		{3, []string{"c"}, true},
+		{4, []string{"d"}, true},
+		{5, nil, false},
+		{6, nil, false},
+		{7, nil, false},
+		{8, nil, false},
+		{9, nil, false},
+	}
+
+	for i, test := range tests {
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+
+	m.Remove(1, "a")
+	m.Remove(4, "d")
+	m.Remove(1, "x")
+	m.Remove(3, "c")
+	m.Remove(2, "x")
+	m.Remove(2, "b")
+
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.KeySet()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Values()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Entries()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("expected %v, got %v", true, actualValue)
+	}
+}
+
+func TestSortedOrder(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(5, "e")
+	m.Put(1, "x")
+	m.Put(3, "c")
+	m.Put(1, "a")
+	m.Put(2, "b")
+
+	if actualValue, expectedValue := m.KeySet(), []int{1, 2, 3, 5}; !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Keys(), []int{1, 1, 2, 3, 5}; !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := m.Values(), []string{"x", "a", "b", "c", "e"}; !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var expectedValue = []multimap.Entry[int, string]{
+		{Key: 1, Value: "x"},
+		{Key: 1, Value: "a"},
+		{Key: 2, Value: "b"},
+		{Key: 3, Value: "c"},
+		{Key: 5, Value: "e"},
+	}
+	if actualValue := m.Entries(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestCustomCompare(t *testing.T) {
+	m := New[string, int](func(a, b string) int {
+		return strings.Compare(b, a)
+	})
+	m.Put("a", 1)
+	m.Put("c", 3)
+	m.Put("b", 2)
+
+	if actualValue, expectedValue := m.KeySet(), []string{"c", "b", "a"}; !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualKey, _, _ := m.Floor("bb"); actualKey != "c" {
+		t.Errorf("expected %v, got %v", "c", actualKey)
+	}
+}
+
+func TestFloorCeiling(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(10, "a")
+	m.Put(20, "b")
+	m.Put(20, "c")
+	m.Put(30, "d")
+
+	tests := []struct {
+		key           int
+		floorKey      int
+		floorValues   []string
+		floorFound    bool
+		ceilingKey    int
+		ceilingValues []string
+		ceilingFound  bool
+	}{
+		{5, 0, nil, false, 10, []string{"a"}, true},
+		{10, 10, []string{"a"}, true, 10, []string{"a"}, true},
+		{15, 10, []string{"a"}, true, 20, []string{"b", "c"}, true},
+		{20, 20, []string{"b", "c"}, true, 20, []string{"b", "c"}, true},
+		{25, 20, []string{"b", "c"}, true, 30, []string{"d"}, true},
+		{35, 30, []string{"d"}, true, 0, nil, false},
+	}
+
+	for i, test := range tests {
+		actualKey, actualValues, actualFound := m.Floor(test.key)
+		if actualKey != test.floorKey || !sameOrder(actualValues, test.floorValues) || actualFound != test.floorFound {
+			t.Errorf("test %d: expected floor %v %v %v, got: %v %v %v", i+1, test.floorKey, test.floorValues, test.floorFound, actualKey, actualValues, actualFound)
+		}
+		actualKey, actualValues, actualFound = m.Ceiling(test.key)
+		if actualKey != test.ceilingKey || !sameOrder(actualValues, test.ceilingValues) || actualFound != test.ceilingFound {
+			t.Errorf("test %d: expected ceiling %v %v %v, got: %v %v %v", i+1, test.ceilingKey, test.ceilingValues, test.ceilingFound, actualKey, actualValues, actualFound)
+		}
+	}
+}
+
+func TestRangeHeadTail(t *testing.T) {
+	m := NewOrdered[int, string]()
+	for i := 9; i >= 0; i-- {
+		m.Put(i, fmt.Sprint(i))
+	}
+	m.Put(4, "four")
+
+	keysOf := func(entries []multimap.Entry[int, string]) []int {
+		keys := make([]int, len(entries))
+		for i, entry := range entries {
+			keys[i] = entry.Key
+		}
+		return keys
+	}
+
+	tests := []struct {
+		name         string
+		actualValue  []multimap.Entry[int, string]
+		expectedKeys []int
+	}{
+		{"Range(3, 6)", m.Range(3, 6), []int{3, 4, 4, 5}},
+		{"Range(6, 3)", m.Range(6, 3), []int{}},
+		{"Range(4, 4)", m.Range(4, 4), []int{}},
+		{"Range(-5, 2)", m.Range(-5, 2), []int{0, 1}},
+		{"Head(3)", m.Head(3), []int{0, 1, 2}},
+		{"Head(0)", m.Head(0), []int{}},
+		{"Tail(8)", m.Tail(8), []int{8, 9}},
+		{"Tail(10)", m.Tail(10), []int{}},
+	}
+
+	for _, test := range tests {
+		if actualKeys := keysOf(test.actualValue); !sameOrder(actualKeys, test.expectedKeys) {
+			t.Errorf("%s: expected %v, got: %v ", test.name, test.expectedKeys, actualKeys)
+		}
+	}
+
+	if actualValue, expectedValue := m.Range(4, 5), []multimap.Entry[int, string]{{Key: 4, Value: "4"}, {Key: 4, Value: "four"}}; !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+}
+
+func TestBalance(t *testing.T) {
+	m := NewOrdered[int, int]()
+	r := rand.New(rand.NewSource(1))
+	model := make(map[int]int)
+
+	for i := 0; i < 10000; i++ {
+		key := r.Intn(500)
+		if r.Intn(3) == 0 {
+			m.RemoveAll(key)
+			delete(model, key)
+		} else {
+			m.Put(key, i)
+			model[key]++
+		}
+	}
+
+	if _, ok := checkBalance(m.root, nil, nil, m.compare); !ok {
+		t.Fatalf("tree is not a balanced search tree")
+	}
+	if actualValue, expectedValue := len(m.KeySet()), len(model); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	size := 0
+	for key, count := range model {
+		size += count
+		if values, _ := m.Get(key); len(values) != count {
+			t.Errorf("key %d: expected %v values, got %v", key, count, len(values))
+		}
+	}
+	if actualValue := m.Size(); actualValue != size {
+		t.Errorf("expected %v, got %v", size, actualValue)
+	}
+}
+
+// Helper function to check the search tree ordering and AVL balance of a subtree.
+func checkBalance[K comparable, V comparable](n *node[K, V], low, high *K, compare func(a, b K) int) (int, bool) {
+	if n == nil {
+		return 0, true
+	}
+	if (low != nil && compare(n.key, *low) <= 0) || (high != nil && compare(n.key, *high) >= 0) {
+		return 0, false
+	}
+	left, ok := checkBalance(n.left, low, &n.key, compare)
+	if !ok {
+		return 0, false
+	}
+	right, ok := checkBalance(n.right, &n.key, high, compare)
+	if !ok || left-right > 1 || right-left > 1 || n.height != max(left, right)+1 {
+		return 0, false
+	}
+	return n.height, true
+}
+
+func TestRemoveAll(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(5, "e")
+	m.Put(6, "f")
+	m.Put(7, "g")
+	m.Put(3, "c")
+	m.Put(4, "d")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+
+	m.RemoveAll(5)
+	m.RemoveAll(6)
+	m.RemoveAll(7)
+	m.RemoveAll(8)
+	m.RemoveAll(5)
+	m.RemoveAll(1)
+	m.RemoveAll(3)
+	m.RemoveAll(2)
+	m.RemoveAll(2)
+	m.RemoveAll(4)
+	m.RemoveAll(9)
+
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Keys()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.KeySet()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Values()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue, expectedValue := fmt.Sprintf("%v", m.Entries()), "[]"; actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if actualValue := m.Size(); actualValue != 0 {
+		t.Errorf("expected %v, got %v", 0, actualValue)
+	}
+	if actualValue := m.Empty(); actualValue != true {
+		t.Errorf("expected %v, got %v", true, actualValue)
+	}
+
+	tests := []struct {
+		key           int
+		expectedValue []string
+		expectedFound bool
+	}{
+		{1, nil, false},
+		{2, nil, false},
+		{3, nil, false},
+		{4, nil, false},
+		{5, nil, false},
+		{6, nil, false},
+		{7, nil, false},
+		{8, nil, false},
+		{9, nil, false},
+	}
+
+	for i, test := range tests {
+		actualValue, actualFound := m.Get(test.key)
+		if !sameElements(actualValue, test.expectedValue) || actualFound != test.expectedFound {
+			t.Errorf("test %d: expected %v, got: %v ", i+1, test.expectedValue, actualValue)
+		}
+	}
+}
+
+func TestConformance(t *testing.T) {
+	multimaptest.RunConformance(t, func() multimap.MultiMap[string, string] {
+		return NewOrdered[string, string]()
+	}, multimaptest.List)
+}
+
+func TestIterators(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.Put(5, "e")
+	m.Put(3, "c")
+	m.Put(1, "x")
+	m.Put(2, "b")
+	m.Put(1, "a")
+	m.PutAll(4, []string{"d", "y"})
+
+	var entries []multimap.Entry[int, string]
+	for key, value := range m.All() {
+		entries = append(entries, multimap.Entry[int, string]{Key: key, Value: value})
+	}
+	if actualValue, expectedValue := entries, m.Entries(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keys []int
+	for key := range m.KeysSeq() {
+		keys = append(keys, key)
+	}
+	if actualValue, expectedValue := keys, m.Keys(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var values []string
+	for value := range m.ValuesSeq() {
+		values = append(values, value)
+	}
+	if actualValue, expectedValue := values, m.Values(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var keySet []int
+	for key, values := range m.Sets() {
+		keySet = append(keySet, key)
+		if expectedValue, _ := m.Get(key); !sameElements(values, expectedValue) {
+			t.Errorf("key %d: expected %v, got %v", key, expectedValue, values)
+		}
+	}
+	if actualValue, expectedValue := keySet, m.KeySet(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	count := 0
+	for range m.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	for range m.Sets() {
+		count++
+		break
+	}
+	if count != 4 {
+		t.Errorf("expected %v, got %v", 4, count)
+	}
+
+	m.Clear()
+
+	for key, value := range m.All() {
+		t.Errorf("unexpected entry %v: %v", key, value)
+	}
+}
+
+func TestClone(t *testing.T) {
+	m := NewOrdered[string, int]()
+	m.PutAll("a", []int{3, 1, 2})
+	m.Put("b", 4)
+
+	c := m.Clone()
+	if !multimap.Equal[string, int](m, c) {
+		t.Errorf("expected %v, got %v", m.Entries(), c.Entries())
+	}
+	if actualValue, expectedValue := multimap.Hash[string, int](c), multimap.Hash[string, int](m); actualValue != expectedValue {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	c.Put("a", 5)
+	c.Remove("b", 4)
+	if actualValue := m.Size(); actualValue != 4 {
+		t.Errorf("expected %v, got %v", 4, actualValue)
+	}
+	if !m.Contains("b", 4) || m.Contains("a", 5) {
+		t.Errorf("expected the multimap to be unaffected by changes of its clone")
+	}
+
+	m.RemoveAll("a")
+	if actualValue, _ := c.Get("a"); !sameOrder(actualValue, []int{3, 1, 2, 5}) {
+		t.Errorf("expected %v, got %v", []int{3, 1, 2, 5}, actualValue)
+	}
+	c.Put("0", 6)
+	if actualValue, expectedValue := c.KeySet(), []string{"0", "a"}; !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+	if _, ok := checkBalance(c.root, nil, nil, c.compare); !ok {
+		t.Errorf("expected the clone to be balanced")
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	m := NewOrdered[int, string]()
+	m.PutAll(2, []string{"b", "a"})
+	m.Put(1, "x")
+	m.Put(3, "")
+
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual := NewOrdered[int, string]()
+	actual.Put(4, "d")
+	if err := actual.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual = NewOrdered[int, string]()
+	if err := gob.NewDecoder(&buf).Decode(actual); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	for i := 0; i < len(data); i++ {
+		if err := actual.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated at %d: expected an error", i)
+		}
+	}
+	if actualValue, expectedValue := actual.Entries(), m.Entries(); !sameOrder(actualValue, expectedValue) {
+		t.Errorf("expected %v, got %v", expectedValue, actualValue)
+	}
+
+	var zero MultiMap[int, string]
+	if err := zero.UnmarshalBinary(data); err == nil {
+		t.Errorf("expected an error without a comparison function")
+	}
+}
+
+// Helper function to check equality of keys/values.
+func sameElements[V comparable](a []V, b []V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av == bv {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check equality of elements including their order.
+func sameOrder[E comparable](a []E, b []E) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to check equality of entries.
+func sameEntries[K comparable, V comparable](a []multimap.Entry[K, V], b []multimap.Entry[K, V]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, av := range a {
+		found := false
+		for _, bv := range b {
+			if av.Key == bv.Key && av.Value == bv.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Utilities for Benchmarking
+func benchmarkGet(b *testing.B, m *MultiMap[int, struct{}], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Get(n)
+		}
+	}
+}
+
+func benchmarkPut(b *testing.B, m *MultiMap[int, struct{}], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Put(n, struct{}{})
+		}
+	}
+}
+
+func benchmarkPutAll(b *testing.B, m *MultiMap[int, struct{}], size int) {
+	v := make([]struct{}, 0)
+	v = append(v, struct{}{})
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.PutAll(n, v)
+		}
+	}
+}
+
+func benchmarkRemove(b *testing.B, m *MultiMap[int, struct{}], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.Remove(n, struct{}{})
+		}
+	}
+}
+
+func benchmarkRemoveAll(b *testing.B, m *MultiMap[int, struct{}], size int) {
+	for i := 0; i < b.N; i++ {
+		for n := 0; n < size; n++ {
+			m.RemoveAll(n)
+		}
+	}
+}
+
+func BenchmarkMultiMapGet100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapGet1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapGet10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapGet100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkGet(b, m, size)
+}
+
+func BenchmarkMultiMapPut100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := NewOrdered[int, struct{}]()
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPut1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPut10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPut100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPut(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := NewOrdered[int, struct{}]()
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapPutAll100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkPutAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemove100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemove1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemove10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemove100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemove(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll100(b *testing.B) {
+	b.StopTimer()
+	size := 100
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll1000(b *testing.B) {
+	b.StopTimer()
+	size := 1000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll10000(b *testing.B) {
+	b.StopTimer()
+	size := 10000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}
+
+func BenchmarkMultiMapRemoveAll100000(b *testing.B) {
+	b.StopTimer()
+	size := 100000
+	m := NewOrdered[int, struct{}]()
+	for n := 0; n < size; n++ {
+		m.Put(n, struct{}{})
+	}
+	b.StartTimer()
+	benchmarkRemoveAll(b, m, size)
+}